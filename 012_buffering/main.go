@@ -2,9 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
+
+	"go_sample_examples/012_buffering/queue"
 )
 
 type MyBuffer struct {
@@ -18,15 +22,17 @@ func (b *MyBuffer) Add(value int) {
 	b.data = append(b.data, value)
 }
 
-func (b *MyBuffer) Get() int {
+// Get removes the oldest value. ok is false when the buffer is empty, so that
+// no value has to be reserved as an "empty" sentinel.
+func (b *MyBuffer) Get() (value int, ok bool) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	if len(b.data) == 0 {
-		return -1 // Indicating buffer is empty
+		return 0, false
 	}
-	value := b.data[0]
-	b.data = b.data[1:]
-	return value
+	value = b.data[0]
+	b.data = b.data[1:] // Reslicing: the consumed front stays allocated until append copies the slice
+	return value, true
 }
 
 func main() {
@@ -147,10 +153,51 @@ func main() {
 	buffer3.Add(3)
 
 	fmt.Println("Buffer content:")
-	fmt.Println(buffer3.Get()) // Output: 1
-	fmt.Println(buffer3.Get()) // Output: 2
-	fmt.Println(buffer3.Get()) // Output: 3
-	fmt.Println(buffer3.Get()) // Output: -1 (empty buffer)
+	fmt.Println(buffer3.Get()) // Output: 1 true
+	fmt.Println(buffer3.Get()) // Output: 2 true
+	fmt.Println(buffer3.Get()) // Output: 3 true
+	fmt.Println(buffer3.Get()) // Output: 0 false (empty buffer)
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+	fmt.Println("-----------------------------------------------------------------------------------")
+
+	// Bounded Blocking Queue
+	// queue.Queue has a capacity, lets producers and consumers wait for room or data with a context,
+	// and reports an empty or full queue with an ok flag
+
+	q := queue.New[int](2)
+
+	fmt.Println("TryPut 1:", q.TryPut(1))
+	fmt.Println("TryPut 2:", q.TryPut(2))
+	fmt.Println("TryPut 3:", q.TryPut(3), "(queue full)")
+
+	// Put waits until a consumer makes room
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		v, _ := q.Take(context.Background())
+		fmt.Println("Consumer took", v)
+	}()
+	err := q.Put(context.Background(), 3)
+	fmt.Println("Put 3 after waiting, err:", err)
+
+	// A context bounds the wait
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	err = q.Put(ctx, 4)
+	cancel()
+	fmt.Println("Put 4 on a full queue:", err)
+
+	// After Close the remaining values can still be taken, then Take reports ErrClosed
+	q.Close()
+	fmt.Println("Put after Close:", q.Put(context.Background(), 5))
+	for {
+		v, err := q.Take(context.Background())
+		if errors.Is(err, queue.ErrClosed) {
+			fmt.Println("Queue closed and empty")
+			break
+		}
+		fmt.Println("Took", v)
+	}
+	fmt.Println(q.TryTake()) // Output: 0 false
 
 	fmt.Println("-----------------------------------------------------------------------------------")
 
@@ -0,0 +1,166 @@
+// Package queue provides a bounded FIFO queue that can be shared between
+// goroutines. Unlike the MyBuffer example in 012_buffering, an empty queue is
+// reported with an ok flag instead of a sentinel value, callers can wait for
+// data or for room, and the ring buffer reuses its memory instead of
+// reslicing.
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by Put after Close, and by Take once a closed queue
+// is empty.
+var ErrClosed = errors.New("queue: closed")
+
+// Queue is a bounded FIFO queue of T.
+type Queue[T any] struct {
+	mu     sync.Mutex
+	buf    []T // ring buffer
+	head   int // index of the oldest item
+	size   int
+	closed bool
+
+	// changed is closed and replaced whenever an item is added or removed
+	// while goroutines are waiting in Put or Take, to wake them.
+	changed chan struct{}
+	waiters int
+}
+
+// New returns an empty queue that holds up to capacity items. It panics if
+// capacity is not positive.
+func New[T any](capacity int) *Queue[T] {
+	if capacity <= 0 {
+		panic("queue: capacity must be positive")
+	}
+	return &Queue[T]{buf: make([]T, capacity), changed: make(chan struct{})}
+}
+
+// Put adds v to the back of the queue, waiting for room until ctx is done.
+func (q *Queue[T]) Put(ctx context.Context, v T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if q.closed {
+			return ErrClosed
+		}
+		if q.size < len(q.buf) {
+			q.push(v)
+			return nil
+		}
+		if err := q.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Take removes the item at the front of the queue, waiting for one until ctx
+// is done. Items put before Close can still be taken; after that Take returns
+// ErrClosed.
+func (q *Queue[T]) Take(ctx context.Context) (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if q.size > 0 {
+			return q.pop(), nil
+		}
+		var zero T
+		if q.closed {
+			return zero, ErrClosed
+		}
+		if err := q.wait(ctx); err != nil {
+			return zero, err
+		}
+	}
+}
+
+// wait releases mu until the queue changes or ctx is done. It must be called
+// with mu held and returns with mu held.
+func (q *Queue[T]) wait(ctx context.Context) error {
+	q.waiters++
+	changed := q.changed
+	q.mu.Unlock()
+
+	var err error
+	select {
+	case <-changed:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	q.mu.Lock()
+	q.waiters--
+	return err
+}
+
+// TryPut adds v if there is room and the queue is open, and reports whether
+// it did.
+func (q *Queue[T]) TryPut(v T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || q.size == len(q.buf) {
+		return false
+	}
+	q.push(v)
+	return true
+}
+
+// TryTake removes the item at the front of the queue if there is one.
+func (q *Queue[T]) TryTake() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.pop(), true
+}
+
+// Len returns the number of queued items.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Cap returns the capacity of the queue.
+func (q *Queue[T]) Cap() int {
+	return len(q.buf)
+}
+
+// Close stops the queue from accepting items and wakes every waiting
+// goroutine. It is safe to call more than once.
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.notify()
+	}
+}
+
+// push and pop must be called with mu held.
+func (q *Queue[T]) push(v T) {
+	q.buf[(q.head+q.size)%len(q.buf)] = v
+	q.size++
+	q.notify()
+}
+
+func (q *Queue[T]) pop() T {
+	var zero T
+	v := q.buf[q.head]
+	q.buf[q.head] = zero // drop the reference so the item can be collected
+	q.head = (q.head + 1) % len(q.buf)
+	q.size--
+	q.notify()
+	return v
+}
+
+func (q *Queue[T]) notify() {
+	if q.waiters > 0 {
+		close(q.changed)
+		q.changed = make(chan struct{})
+	}
+}
@@ -0,0 +1,141 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFIFOAcrossWraparound(t *testing.T) {
+	q := New[int](3)
+	next := 0
+	for i := range 10 {
+		if !q.TryPut(i) {
+			t.Fatalf("TryPut(%d) on a queue with room returned false", i)
+		}
+		if i%2 == 1 { // keep the ring partly full so head wraps around
+			for q.Len() > 1 {
+				if v, ok := q.TryTake(); !ok || v != next {
+					t.Fatalf("TryTake = %d, %v, want %d, true", v, ok, next)
+				}
+				next++
+			}
+		}
+	}
+}
+
+func TestTryPutFullAndTryTakeEmpty(t *testing.T) {
+	q := New[string](1)
+	if _, ok := q.TryTake(); ok {
+		t.Fatal("TryTake on an empty queue returned ok")
+	}
+	q.TryPut("a")
+	if q.TryPut("b") {
+		t.Fatal("TryPut on a full queue returned true")
+	}
+	if q.Len() != 1 || q.Cap() != 1 {
+		t.Fatalf("Len %d and Cap %d, want 1 and 1", q.Len(), q.Cap())
+	}
+}
+
+func TestPutWaitsForRoom(t *testing.T) {
+	q := New[int](1)
+	q.TryPut(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Put(ctx, 2); err != context.DeadlineExceeded {
+		t.Fatalf("Put on a full queue = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- q.Put(context.Background(), 2) }()
+	if v, err := q.Take(context.Background()); err != nil || v != 1 {
+		t.Fatalf("Take = %d, %v, want 1", v, err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Put after Take made room: %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	q := New[int](2)
+	q.TryPut(1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Blocked on a full queue until Close
+		q.TryPut(2)
+		if err := q.Put(context.Background(), 3); err != ErrClosed {
+			t.Errorf("Put during Close = %v, want %v", err, ErrClosed)
+		}
+	}()
+	for q.Len() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	q.Close()
+	wg.Wait()
+
+	for _, want := range []int{1, 2} {
+		if v, err := q.Take(context.Background()); err != nil || v != want {
+			t.Fatalf("Take after Close = %d, %v, want %d", v, err, want)
+		}
+	}
+	if _, err := q.Take(context.Background()); err != ErrClosed {
+		t.Fatalf("Take on a closed, empty queue = %v, want %v", err, ErrClosed)
+	}
+}
+
+// reslicingBuffer is the original MyBuffer from 012_buffering, which drops
+// the front of its slice on every Get.
+type reslicingBuffer struct {
+	data []int
+	mu   sync.Mutex
+}
+
+func (b *reslicingBuffer) Add(value int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, value)
+}
+
+func (b *reslicingBuffer) Get() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.data) == 0 {
+		return -1 // Indicating buffer is empty
+	}
+	value := b.data[0]
+	b.data = b.data[1:]
+	return value
+}
+
+// BenchmarkReslicingBuffer adds and gets one value per iteration with a
+// backlog of 64 values.
+func BenchmarkReslicingBuffer(b *testing.B) {
+	buf := &reslicingBuffer{}
+	for i := range 64 {
+		buf.Add(i)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Add(i)
+		buf.Get()
+	}
+}
+
+// BenchmarkQueue does the same with the ring buffer.
+func BenchmarkQueue(b *testing.B) {
+	q := New[int](128)
+	for i := range 64 {
+		q.TryPut(i)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q.TryPut(i)
+		q.TryTake()
+	}
+}
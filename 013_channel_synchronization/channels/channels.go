@@ -0,0 +1,128 @@
+// Package channels provides the fan-in and fan-out helpers that
+// 013_channel_synchronization and 019_range_over_channel write by hand. Every
+// helper owns the channels it returns: it closes them when its input is
+// exhausted or when ctx is done, so callers never need a separate
+// wg.Wait(); close(ch) goroutine.
+package channels
+
+import (
+	"context"
+	"sync"
+)
+
+// OrDone returns a channel that receives the values of in until in is closed
+// or ctx is done, so a range loop over it also stops on cancellation.
+func OrDone[T any](ctx context.Context, in <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Merge fans in: it returns a channel that receives the values of all chans
+// and is closed once every one of them is closed, or when ctx is done. Values
+// from one input keep their order; values from different inputs interleave.
+func Merge[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func() {
+			defer wg.Done()
+			for v := range OrDone(ctx, ch) {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Split fans out: it returns n channels and hands every value of in to one of
+// them, whichever is ready to receive first, so a slow consumer gets fewer
+// values. All of them are closed when in is closed or ctx is done.
+func Split[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	n = max(n, 1)
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for _, out := range outs {
+		go func() {
+			defer wg.Done()
+			defer close(out)
+			for {
+				var v T
+				var ok bool
+				select {
+				case v, ok = <-in:
+					if !ok {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return result
+}
+
+// Tee returns two channels that both receive every value of in. Each value is
+// delivered to both before the next one is read, so both must be consumed.
+// They are closed when in is closed or ctx is done.
+func Tee[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
+	out1, out2 := make(chan T), make(chan T)
+	go func() {
+		defer close(out1)
+		defer close(out2)
+		for v := range OrDone(ctx, in) {
+			// Send to whichever is ready first, then to the other one
+			a, b := out1, out2
+			for range 2 {
+				select {
+				case a <- v:
+					a = nil
+				case b <- v:
+					b = nil
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out1, out2
+}
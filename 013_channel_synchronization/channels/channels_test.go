@@ -0,0 +1,191 @@
+package channels
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// generate returns a channel that receives values and is then closed.
+func generate(values ...int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for _, v := range values {
+			ch <- v
+		}
+	}()
+	return ch
+}
+
+// forever returns a channel that counts up until ctx is done, and a channel
+// that is closed once the producer has stopped. The first is never closed, so
+// only cancellation can stop the helpers reading from it.
+func forever(ctx context.Context) (<-chan int, <-chan struct{}) {
+	ch := make(chan int)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case ch <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, stopped
+}
+
+// drain reads ch until it is closed and fails t if that takes more than a
+// second.
+func drain[T any](t *testing.T, ch <-chan T) []T {
+	t.Helper()
+	var got []T
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return got
+			}
+			got = append(got, v)
+		case <-timeout:
+			t.Fatal("channel was not closed")
+		}
+	}
+}
+
+func TestOrDone(t *testing.T) {
+	got := drain(t, OrDone(context.Background(), generate(1, 2, 3)))
+	if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestOrDoneCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in, stopped := forever(ctx)
+	out := OrDone(ctx, in)
+	<-out
+	<-out
+	cancel()
+	// out is closed by OrDone's goroutine on its way out, so once it is
+	// closed and the producer has stopped, nothing is left running
+	drain(t, out)
+	<-stopped
+}
+
+func TestMerge(t *testing.T) {
+	out := Merge(context.Background(), generate(1, 2, 3), generate(10, 20), generate())
+	got := drain(t, out)
+
+	var first, second []int
+	for _, v := range got {
+		if v < 10 {
+			first = append(first, v)
+		} else {
+			second = append(second, v)
+		}
+	}
+	if !slices.Equal(first, []int{1, 2, 3}) || !slices.Equal(second, []int{10, 20}) {
+		t.Fatalf("got %v, want 1 2 3 and 10 20 each in order", got)
+	}
+}
+
+func TestMergeNoInputs(t *testing.T) {
+	if got := drain(t, Merge[int](context.Background())); len(got) != 0 {
+		t.Fatalf("got %v, want nothing", got)
+	}
+}
+
+func TestMergeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in1, stopped1 := forever(ctx)
+	in2, stopped := forever(ctx)
+	out := Merge(ctx, in1, in2)
+	<-out
+	cancel()
+	drain(t, out)
+	<-stopped1
+	<-stopped
+}
+
+// readAll drains every channel concurrently, as Split requires.
+func readAll(t *testing.T, outs []<-chan int) [][]int {
+	t.Helper()
+	got := make([][]int, len(outs))
+	var wg sync.WaitGroup
+	for i, out := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range out {
+				got[i] = append(got[i], v)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("outputs were not closed")
+	}
+	return got
+}
+
+func TestSplit(t *testing.T) {
+	outs := Split(context.Background(), generate(1, 2, 3, 4, 5, 6, 7, 8), 3)
+	if len(outs) != 3 {
+		t.Fatalf("got %d outputs, want 3", len(outs))
+	}
+
+	// Every value goes to exactly one output, in order within it
+	var all []int
+	for _, values := range readAll(t, outs) {
+		if !slices.IsSorted(values) {
+			t.Errorf("output received %v out of order", values)
+		}
+		all = append(all, values...)
+	}
+	slices.Sort(all)
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8}; !slices.Equal(all, want) {
+		t.Fatalf("got %v, want %v", all, want)
+	}
+}
+
+func TestSplitCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in, stopped := forever(ctx)
+	outs := Split(ctx, in, 2)
+	<-outs[0]
+	cancel()
+	readAll(t, outs)
+	<-stopped
+}
+
+func TestTee(t *testing.T) {
+	out1, out2 := Tee(context.Background(), generate(1, 2, 3))
+	got := readAll(t, []<-chan int{out1, out2})
+	want := []int{1, 2, 3}
+	if !slices.Equal(got[0], want) || !slices.Equal(got[1], want) {
+		t.Fatalf("got %v and %v, want %v on both", got[0], got[1], want)
+	}
+}
+
+func TestTeeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in, stopped := forever(ctx)
+	out1, out2 := Tee(ctx, in)
+	<-out1
+	<-out2
+	cancel()
+	readAll(t, []<-chan int{out1, out2})
+	<-stopped
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"sort"
 	"sync"
 	"time"
+
+	"go_sample_examples/013_channel_synchronization/channels"
 )
 
 func worker(done chan bool) {
@@ -155,6 +160,87 @@ func main() {
 	// Wait for the worker to finish
 	<-done
 	fmt.Println("All jobs processed.")
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+	fmt.Println("-----------------------------------------------------------------------------------")
+
+	// Fan-Out / Fan-In with the channels Package
+	// Split, Merge, Tee and OrDone close their channels themselves and stop on cancellation,
+	// so there is no separate wg.Wait(); close(ch) goroutine
+
+	ctx := context.Background()
+
+	// Fan out five jobs to three workers, then fan their results back in
+	source := make(chan int)
+	go func() {
+		defer close(source)
+		for j := 1; j <= 5; j++ {
+			source <- j
+		}
+	}()
+
+	var doubled []<-chan int
+	for w, in := range channels.Split(ctx, source, 3) {
+		out := make(chan int)
+		go func() {
+			defer close(out)
+			for j := range in {
+				fmt.Printf("Worker %d: doubling job %d\n", w+1, j)
+				out <- j * 2
+			}
+		}()
+		doubled = append(doubled, out)
+	}
+
+	// Tee sends every merged result both to a collector and to a running total
+	collected, totals := channels.Tee(ctx, channels.Merge(ctx, doubled...))
+	sum := make(chan int)
+	go func() {
+		total := 0
+		for v := range totals {
+			total += v
+		}
+		sum <- total
+	}()
+
+	var merged []int
+	for v := range collected {
+		merged = append(merged, v)
+	}
+	sort.Ints(merged)
+	fmt.Println("Merged results:", merged, "Total:", <-sum)
+
+	// Cancelling the context stops every helper, even with an endless producer
+	time.Sleep(50 * time.Millisecond) // Let the helpers above finish closing their channels
+	before := runtime.NumGoroutine()
+	cancelCtx, cancel := context.WithCancel(ctx)
+
+	endless := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case endless <- i:
+			case <-cancelCtx.Done():
+				return
+			}
+		}
+	}()
+
+	parts := channels.Split(cancelCtx, endless, 2)
+	left, right := channels.Tee(cancelCtx, channels.Merge(cancelCtx, parts...))
+	received := 0
+	for range channels.OrDone(cancelCtx, left) {
+		<-right
+		if received++; received == 10 {
+			cancel()
+		}
+	}
+	for range right {
+	}
+
+	time.Sleep(50 * time.Millisecond) // Give the stopped goroutines time to exit
+	fmt.Println("Stopped after receiving at least 10 values:", received >= 10)
+	fmt.Println("Goroutines left behind:", runtime.NumGoroutine()-before)
 	fmt.Println("-----------------------------------------------------------------------------------")
 
 }
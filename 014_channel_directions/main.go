@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"sort"
+	"sync"
 	"time"
+
+	"go_sample_examples/014_channel_directions/pipeline"
+	"go_sample_examples/014_channel_directions/reqreply"
 )
 
 // sendOnly sends an integer to the channel (send-only)
@@ -51,12 +58,15 @@ func printNumbers1(in <-chan int) {
 	}
 }
 
-func pingPong(pings <-chan string, pongs chan<- string) {
+func pingPong(ctx context.Context, pings <-chan string, pongs chan<- string) {
 	for {
 		select {
 		case msg := <-pings:
 			fmt.Println("Received ping:", msg)
 			pongs <- "pong"
+		case <-ctx.Done():
+			fmt.Println("pingPong stopped")
+			return
 		}
 	}
 }
@@ -128,19 +138,120 @@ func main() {
 	fmt.Println("-----------------------------------------------------------------------------------")
 	fmt.Println("-----------------------------------------------------------------------------------")
 
+	// Generic Pipeline Builder
+	// The pipeline package generalizes the stages above: typed stages, per-stage workers and buffers,
+	// and cancellation that reaches every stage
+
+	p := pipeline.New(context.Background())
+	numbers := pipeline.From(p, 1, 2, 3, 4, 5)
+	squares := pipeline.Map(numbers, func(ctx context.Context, n int) (int, error) {
+		return n * n, nil
+	}, pipeline.WithWorkers(3), pipeline.WithBuffer(5))
+	odd := pipeline.Filter(squares, func(n int) bool { return n%2 == 1 })
+
+	results, err := pipeline.Collect(odd)
+	sort.Ints(results) // Three workers may finish out of order
+	fmt.Println("Odd squares:", results, "Error:", err)
+
+	// The first error cancels every stage, and the stages drain their input so nothing leaks
+	before := runtime.NumGoroutine()
+
+	p = pipeline.New(context.Background())
+	endless := pipeline.Source(p, func(ctx context.Context, emit func(int) error) error {
+		for i := 1; ; i++ {
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+	}, pipeline.WithName("generate"))
+	checked := pipeline.Map(endless, func(ctx context.Context, n int) (int, error) {
+		if n == 4 {
+			return 0, fmt.Errorf("number %d rejected", n)
+		}
+		return n, nil
+	}, pipeline.WithName("validate"), pipeline.WithWorkers(2))
+	pipeline.ForEach(checked, func(ctx context.Context, n int) error {
+		return nil
+	})
+
+	fmt.Println("Error:", p.Wait())
+	fmt.Println("Goroutines left behind:", runtime.NumGoroutine()-before)
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+	fmt.Println("-----------------------------------------------------------------------------------")
+
 	// Bidirectional Channels with Select Statement
 	// Handle both sending and receiving in a function, particularly when using the select statement
 
 	pings := make(chan string)
 	pongs := make(chan string)
+	stopped := make(chan struct{})
 
-	go pingPong(pings, pongs)
+	pingCtx, stopPing := context.WithCancel(context.Background())
+	go func() {
+		pingPong(pingCtx, pings, pongs)
+		close(stopped)
+	}()
 
 	pings <- "ping"
 	fmt.Println("Sent ping")
 
 	fmt.Println("Received pong:", <-pongs)
 
+	stopPing() // pingPong has an exit path now
+	<-stopped
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+	fmt.Println("-----------------------------------------------------------------------------------")
+
+	// Request/Reply on Top of Ping/Pong
+	// Several callers share one responder goroutine; correlation IDs route each reply to its caller,
+	// and every call has its own timeout
+
+	client := reqreply.NewClient(context.Background(), func(ctx context.Context, ping string) (string, error) {
+		if ping == "slow" {
+			select {
+			case <-time.After(200 * time.Millisecond):
+			case <-ctx.Done(): // The caller's deadline reaches the handler
+				return "", ctx.Err()
+			}
+		}
+		return "pong for " + ping, nil
+	}, reqreply.WithTimeout(time.Second))
+
+	callers := []string{"alice", "bob", "slow", "carol"}
+	replies := make([]string, len(callers))
+	var wg sync.WaitGroup
+	for i, name := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			if name == "slow" {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, 50*time.Millisecond) // Tighter deadline for this call
+				defer cancel()
+			}
+			reply, err := client.Call(ctx, name)
+			if err != nil {
+				reply = "error: " + err.Error()
+			}
+			replies[i] = reply
+		}()
+	}
+	wg.Wait()
+
+	for i, name := range callers {
+		fmt.Printf("Caller %s got: %s\n", name, replies[i])
+	}
+
+	client.Close()
+
+	_, err = client.Call(context.Background(), "late")
+	fmt.Println("Call after Close:", err)
+	stats := client.Stats()
+	fmt.Printf("Calls: %d, Failed: %d\n", stats.Calls, stats.Failed)
+
 	fmt.Println("-----------------------------------------------------------------------------------")
 	fmt.Println("-----------------------------------------------------------------------------------")
 
@@ -0,0 +1,246 @@
+// Package pipeline builds multi-stage channel pipelines like the
+// generateNumbers → squareNumbers → printNumbers1 chain in
+// 014_channel_directions, for any element type. Every stage can run several
+// goroutines and has its own output buffer. The first error cancels the whole
+// pipeline, and stages drain their input when they stop so no upstream
+// goroutine is left blocked on a send.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Pipeline owns the goroutines of all of its stages.
+type Pipeline struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	err    error
+	stages int
+}
+
+// New returns an empty pipeline that stops when ctx is done.
+func New(ctx context.Context) *Pipeline {
+	inner, cancel := context.WithCancel(ctx)
+	return &Pipeline{parent: ctx, ctx: inner, cancel: cancel}
+}
+
+// Context returns the context shared by the stages. It is cancelled on the
+// first error.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Wait blocks until every stage has finished and returns the first error, or
+// the parent context's error if it was cancelled.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	return p.parent.Err()
+}
+
+// fail records the first error and cancels every stage.
+func (p *Pipeline) fail(name string, err error) {
+	p.mu.Lock()
+	if p.err == nil && p.parent.Err() == nil {
+		p.err = fmt.Errorf("pipeline: %s: %w", name, err)
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+func (p *Pipeline) newStage(opts []Option) stageConfig {
+	p.mu.Lock()
+	p.stages++
+	cfg := stageConfig{name: fmt.Sprintf("stage %d", p.stages), workers: 1}
+	p.mu.Unlock()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+type stageConfig struct {
+	name    string
+	workers int
+	buffer  int
+}
+
+// Option configures a stage.
+type Option func(*stageConfig)
+
+// WithWorkers runs the stage on n goroutines. Output order is only preserved
+// with a single worker, which is the default.
+func WithWorkers(n int) Option {
+	return func(c *stageConfig) {
+		c.workers = max(n, 1)
+	}
+}
+
+// WithBuffer sets the size of the stage's output channel. The default is 0.
+func WithBuffer(n int) Option {
+	return func(c *stageConfig) {
+		c.buffer = max(n, 0)
+	}
+}
+
+// WithName names the stage in the errors it returns.
+func WithName(name string) Option {
+	return func(c *stageConfig) {
+		c.name = name
+	}
+}
+
+// Stage is the output of a pipeline stage, to be consumed by the next one.
+type Stage[T any] struct {
+	p  *Pipeline
+	ch <-chan T
+}
+
+// run starts cfg.workers goroutines running fn and closes out once all of
+// them are done. A worker that returns an error fails the pipeline, and then
+// calls drainInput, if any, so the upstream stage can finish.
+func run[T any](p *Pipeline, cfg stageConfig, drainInput func(), fn func(out chan<- T) error) *Stage[T] {
+	out := make(chan T, cfg.buffer)
+	var workers sync.WaitGroup
+	for range cfg.workers {
+		workers.Add(1)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer workers.Done()
+			if err := fn(out); err != nil {
+				p.fail(cfg.name, err)
+			}
+			if drainInput != nil {
+				drainInput()
+			}
+		}()
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		workers.Wait()
+		close(out)
+	}()
+	return &Stage[T]{p: p, ch: out}
+}
+
+// send delivers v unless the pipeline is cancelled first.
+func send[T any](ctx context.Context, out chan<- T, v T) error {
+	select {
+	case out <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain discards what is left in the stage so that its goroutines can finish.
+func (s *Stage[T]) drain() {
+	for range s.ch {
+	}
+}
+
+// Source starts a stage that produces values by calling emit. emit returns an
+// error once the pipeline is cancelled, and fn should then return.
+func Source[T any](p *Pipeline, fn func(ctx context.Context, emit func(T) error) error, opts ...Option) *Stage[T] {
+	cfg := p.newStage(opts)
+	cfg.workers = 1
+	return run(p, cfg, nil, func(out chan<- T) error {
+		err := fn(p.ctx, func(v T) error { return send(p.ctx, out, v) })
+		if p.ctx.Err() != nil {
+			return nil // cancelled: the cause is already recorded
+		}
+		return err
+	})
+}
+
+// From starts a stage that produces values.
+func From[T any](p *Pipeline, values ...T) *Stage[T] {
+	return Source(p, func(ctx context.Context, emit func(T) error) error {
+		for _, v := range values {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Map starts a stage that applies fn to every value of in.
+func Map[In, Out any](in *Stage[In], fn func(ctx context.Context, v In) (Out, error), opts ...Option) *Stage[Out] {
+	p := in.p
+	cfg := p.newStage(opts)
+	return run(p, cfg, in.drain, func(out chan<- Out) error {
+		for v := range in.ch {
+			if p.ctx.Err() != nil {
+				return nil
+			}
+			r, err := fn(p.ctx, v)
+			if err != nil {
+				return err
+			}
+			if send(p.ctx, out, r) != nil {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Filter starts a stage that only passes on the values for which keep returns
+// true.
+func Filter[T any](in *Stage[T], keep func(v T) bool, opts ...Option) *Stage[T] {
+	p := in.p
+	cfg := p.newStage(opts)
+	return run(p, cfg, in.drain, func(out chan<- T) error {
+		for v := range in.ch {
+			if !keep(v) {
+				continue
+			}
+			if send(p.ctx, out, v) != nil {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// ForEach ends the pipeline with a stage that calls fn for every value of in.
+// Call Wait on the pipeline to run it to completion.
+func ForEach[T any](in *Stage[T], fn func(ctx context.Context, v T) error, opts ...Option) {
+	p := in.p
+	cfg := p.newStage(opts)
+	run(p, cfg, in.drain, func(chan<- struct{}) error {
+		for v := range in.ch {
+			if p.ctx.Err() != nil {
+				return nil
+			}
+			if err := fn(p.ctx, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Collect waits for the pipeline and returns the values that reached in.
+func Collect[T any](in *Stage[T]) ([]T, error) {
+	var values []T
+	for v := range in.ch {
+		values = append(values, v)
+	}
+	return values, in.p.Wait()
+}
@@ -0,0 +1,236 @@
+// Package reqreply builds request/reply messaging on the ping/pong pattern
+// from 014_channel_directions: one responder goroutine receives requests on a
+// receive-only channel and answers on a send-only channel. Every message
+// carries a correlation ID so that many concurrent callers can share the
+// responder and each one gets its own reply.
+package reqreply
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by Call once the client has been shut down.
+var ErrClosed = errors.New("reqreply: client closed")
+
+// Message is a request or a reply. A reply has the ID of its request.
+type Message[T any] struct {
+	ID   uint64
+	Body T
+	Err  error
+	// Ctx is the caller's context on a request. Serve derives the handler's
+	// context from it, so the handler stops when the caller gives up. It is
+	// nil on replies.
+	Ctx context.Context
+}
+
+// Handler answers one request.
+type Handler[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// Serve answers the requests from requests on replies until ctx is done. It
+// handles one request at a time, like pingPong. A request whose caller has
+// already given up is skipped; otherwise the handler's context is cancelled
+// when either ctx or the caller's context is done.
+func Serve[Req, Resp any](ctx context.Context, requests <-chan Message[Req], replies chan<- Message[Resp], handler Handler[Req, Resp]) {
+	for {
+		select {
+		case req := <-requests:
+			if req.Ctx != nil && req.Ctx.Err() != nil {
+				continue
+			}
+			body, err := handle(ctx, req, handler)
+			select {
+			case replies <- Message[Resp]{ID: req.ID, Body: body, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handle runs handler with a context that ends with ctx or with the
+// caller's context, and carries the caller's deadline.
+func handle[Req, Resp any](ctx context.Context, req Message[Req], handler Handler[Req, Resp]) (Resp, error) {
+	if req.Ctx == nil {
+		return handler(ctx, req.Body)
+	}
+	var (
+		hctx   context.Context
+		cancel context.CancelFunc
+	)
+	if deadline, ok := req.Ctx.Deadline(); ok {
+		hctx, cancel = context.WithDeadline(ctx, deadline)
+	} else {
+		hctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	stop := context.AfterFunc(req.Ctx, cancel)
+	defer stop()
+	return handler(hctx, req.Body)
+}
+
+type options struct {
+	timeout time.Duration
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+// WithTimeout bounds every call that does not already have an earlier
+// deadline. The default is no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// Stats counts the calls made through a Client.
+type Stats struct {
+	Calls    int // calls that got a reply
+	Failed   int // calls that timed out, were cancelled or hit a closed client
+	Late     int // replies that arrived after their caller gave up
+	InFlight int // calls waiting for a reply
+}
+
+// Client sends requests to a responder goroutine running Serve and routes
+// the replies back to their callers. It is safe for concurrent use.
+type Client[Req, Resp any] struct {
+	opts     options
+	ctx      context.Context
+	cancel   context.CancelFunc
+	requests chan Message[Req]
+	replies  chan Message[Resp]
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan Message[Resp]
+	stats   Stats
+}
+
+// NewClient starts a responder goroutine running handler and returns a
+// client for it. The responder stops when ctx is done or Close is called.
+func NewClient[Req, Resp any](ctx context.Context, handler Handler[Req, Resp], opts ...Option) *Client[Req, Resp] {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &Client[Req, Resp]{
+		ctx:      ctx,
+		cancel:   cancel,
+		requests: make(chan Message[Req]),
+		replies:  make(chan Message[Resp]),
+		pending:  make(map[uint64]chan Message[Resp]),
+	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		Serve(ctx, c.requests, c.replies, handler)
+	}()
+	go func() {
+		defer c.wg.Done()
+		c.route()
+	}()
+	return c
+}
+
+// route hands every reply to the caller waiting for its ID.
+func (c *Client[Req, Resp]) route() {
+	for {
+		select {
+		case reply := <-c.replies:
+			c.mu.Lock()
+			ch, ok := c.pending[reply.ID]
+			delete(c.pending, reply.ID)
+			if !ok {
+				c.stats.Late++
+			}
+			c.mu.Unlock()
+			if ok {
+				ch <- reply // buffered, never blocks
+			}
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// Call sends req to the responder and waits for its reply, until ctx is done,
+// the client timeout passes or the client is closed.
+func (c *Client[Req, Resp]) Call(ctx context.Context, req Req) (Resp, error) {
+	if c.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.timeout)
+		defer cancel()
+	}
+
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan Message[Resp], 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	reply, err := c.roundTrip(ctx, Message[Req]{ID: id, Body: req, Ctx: ctx}, ch)
+
+	c.mu.Lock()
+	if err != nil {
+		delete(c.pending, id)
+		c.stats.Failed++
+	} else {
+		c.stats.Calls++
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return reply.Body, reply.Err
+}
+
+func (c *Client[Req, Resp]) roundTrip(ctx context.Context, req Message[Req], ch <-chan Message[Resp]) (Message[Resp], error) {
+	select {
+	case c.requests <- req:
+	case <-ctx.Done():
+		return Message[Resp]{}, ctx.Err()
+	case <-c.ctx.Done():
+		return Message[Resp]{}, ErrClosed
+	}
+
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-ctx.Done():
+		return Message[Resp]{}, ctx.Err()
+	case <-c.ctx.Done():
+		return Message[Resp]{}, ErrClosed
+	}
+}
+
+// Stats returns the call counters.
+func (c *Client[Req, Resp]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.InFlight = len(c.pending)
+	return s
+}
+
+// Done is closed when the client has been shut down.
+func (c *Client[Req, Resp]) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Close stops the responder and fails the calls still waiting with
+// ErrClosed. It waits for the responder to return, so a handler that ignores
+// its context delays Close.
+func (c *Client[Req, Resp]) Close() {
+	c.cancel()
+	c.wg.Wait()
+}
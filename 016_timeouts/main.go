@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -14,10 +16,12 @@ func main() {
 	// Basic Timeout with time.After
 	// Set a timeout for receiving a message from a channel
 
+	clk := clock.Real()
+
 	ch := make(chan string)
 
 	go func() {
-		time.Sleep(2 * time.Second)
+		clk.Sleep(2 * time.Second)
 		ch <- "result"
 	}()
 
@@ -26,15 +30,15 @@ func main() {
 				case res := <-ch:
 			         If a value is available in the channel ch, it is received, assigned to res, and "Received: res" is printed
 
-				case <-time.After(1 * time.Second):
-		             This sets a timeout using time.After(1 * time.Second). If no value is received from ch within 1 second, the timeout case will trigger, printing "Timeout: No data received"
+				case <-clk.After(1 * time.Second):
+		             This sets a timeout using clk.After(1 * time.Second). If no value is received from ch within 1 second, the timeout case will trigger, printing "Timeout: No data received"
 
 	*/
 
 	select {
 	case res := <-ch:
 		fmt.Println("Received:", res)
-	case <-time.After(1 * time.Second): // Timeout after 1 second
+	case <-clk.After(1 * time.Second): // Timeout after 1 second
 		fmt.Println("Timeout: No response received")
 	}
 
@@ -43,14 +47,14 @@ func main() {
 	ch = make(chan string)
 
 	go func() {
-		time.Sleep(500 * time.Millisecond) // Sleep for 0.5 seconds
+		clk.Sleep(500 * time.Millisecond) // Sleep for 0.5 seconds
 		ch <- "result"
 	}()
 
 	select {
 	case res := <-ch:
 		fmt.Println("Received:", res)
-	case <-time.After(1 * time.Second): // Timeout after 1 second
+	case <-clk.After(1 * time.Second): // Timeout after 1 second
 		fmt.Println("Timeout: No response received")
 	}
 
@@ -59,14 +63,14 @@ func main() {
 	ch = make(chan string)
 
 	go func() {
-		time.Sleep(2 * time.Second) // Sleep for 2 seconds
+		clk.Sleep(2 * time.Second) // Sleep for 2 seconds
 		ch <- "result"
 	}()
 
 	select {
 	case res := <-ch:
 		fmt.Println("Received:", res)
-	case <-time.After(3 * time.Second): // Timeout after 3 seconds
+	case <-clk.After(3 * time.Second): // Timeout after 3 seconds
 		fmt.Println("Timeout: No response received")
 	}
 
@@ -78,14 +82,14 @@ func main() {
 	ch = make(chan string)
 
 	go func() {
-		time.Sleep(2 * time.Second)
+		clk.Sleep(2 * time.Second)
 		ch <- "data"
 	}()
 
 	select {
 	case msg := <-ch:
 		fmt.Println("Received:", msg)
-	case <-time.After(1 * time.Second):
+	case <-clk.After(1 * time.Second):
 		fmt.Println("Timeout: No data received within 1 second")
 	}
 
@@ -97,7 +101,7 @@ func main() {
 	ch = make(chan string)
 
 	go func() {
-		time.Sleep(3 * time.Second)
+		clk.Sleep(3 * time.Second)
 		ch <- "response"
 	}()
 
@@ -106,7 +110,7 @@ func main() {
 		case res := <-ch:
 			fmt.Println("Received:", res)
 			return
-		case <-time.After(1 * time.Second):
+		case <-clk.After(1 * time.Second):
 			fmt.Println("Attempt", i+1, "timed out")
 		}
 	}
@@ -137,13 +141,13 @@ func main() {
 	// The context package provides a more structured way to handle timeouts, especially in more complex programs
 
 	// Create a context with a timeout of 2 seconds
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := clock.WithTimeout(context.Background(), clk, 2*time.Second)
 	defer cancel()
 
 	ch = make(chan string)
 
 	go func() {
-		time.Sleep(3 * time.Second)
+		clk.Sleep(3 * time.Second)
 		select {
 		case ch <- "done":
 		case <-ctx.Done(): // Stop sending if the context is done
@@ -167,12 +171,12 @@ func main() {
 	ch2 := make(chan string)
 
 	go func() {
-		time.Sleep(2 * time.Second)
+		clk.Sleep(2 * time.Second)
 		ch1 <- "data from ch1"
 	}()
 
 	go func() {
-		time.Sleep(1 * time.Second)
+		clk.Sleep(1 * time.Second)
 		ch2 <- "data from ch2"
 	}()
 
@@ -181,7 +185,7 @@ func main() {
 		fmt.Println("Received from ch1:", msg1)
 	case msg2 := <-ch2:
 		fmt.Println("Received from ch2:", msg2)
-	case <-time.After(1 * time.Second):
+	case <-clk.After(1 * time.Second):
 		fmt.Println("Timeout: No data received within 1 second")
 	}
 
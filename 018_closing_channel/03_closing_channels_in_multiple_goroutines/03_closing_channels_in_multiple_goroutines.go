@@ -1,15 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+
+	"go_sample_examples/018_closing_channel/safechan"
 )
 
 // Each worker sends the integers 0, 1, 2 to the channel ch
-func worker(ch chan int, wg *sync.WaitGroup) {
+func worker(ch *safechan.Chan[int], wg *sync.WaitGroup) {
 	defer wg.Done() // wg.Done() is called to indicate that the worker has finished its task
 	for i := 0; i < 3; i++ {
-		ch <- i
+		if err := ch.Send(context.Background(), i); err != nil {
+			return // The channel was closed; stop instead of panicking
+		}
+	}
+}
+
+// Each producer sends until the channel is closed by someone else
+func producer(id int, ch *safechan.Chan[int], wg *sync.WaitGroup) {
+	defer wg.Done()
+	for i := 0; ; i++ {
+		if err := ch.Send(context.Background(), id*100+i); errors.Is(err, safechan.ErrClosed) {
+			return
+		}
 	}
 }
 
@@ -21,35 +37,64 @@ func main() {
 	// The channel is closed after all the workers finish their tasks.
 	// The sync.WaitGroup is used to synchronize the workers and close the channel only after all of them have finished
 
-	// ch: A channel of type int used for communication between goroutines.
-	ch := make(chan int)
+	// ch: A safechan.Chan wraps a channel of type int so that it can be shared by several senders and closed safely.
+	ch := safechan.New[int](0)
 
 	// wg: A sync.WaitGroup to synchronize the completion of multiple goroutines.
 	var wg sync.WaitGroup
 
 	// Start 3 worker goroutines
-	// // Three worker goroutines are started. Each worker will send values (0, 1, 2) to the channel ch
+	// Three worker goroutines are started. Each worker will send values (0, 1, 2) to the channel ch
 	for i := 0; i < 3; i++ {
 		wg.Add(1) // wg.Add(1) increments the wait group counter by 1 for each worker goroutine
 		go worker(ch, &wg)
 	}
 
 	go func() {
-		wg.Wait() // A separate goroutine waits for all worker goroutines to complete using wg.Wait()
-		close(ch) // Close the channel when all workers are done
+		wg.Wait()  // A separate goroutine waits for all worker goroutines to complete using wg.Wait()
+		ch.Close() // Close the channel when all workers are done
 	}()
 
 	// Receiving values until the channel is closed
-	for value := range ch {
+	for value := range ch.C() {
 		fmt.Println("Received:", value)
 	}
 
 	fmt.Println("All workers done, channel closed.")
 
+	// Closing again is a no-op, and sending after close returns an error instead of panicking
+	fmt.Println("Closed by second Close:", ch.Close())
+	fmt.Println("TrySend after Close:", ch.TrySend(42))
+
 	/*
 		Goroutine Execution Order
 			Each worker goroutine sends three integers (0, 1, 2) to the channel ch.
 			Since the goroutines execute concurrently, the exact order of sending is not guaranteed
 	*/
 
+	fmt.Println("-----------------------------------")
+
+	// Closing from the Receiving Side
+
+	// Here the receiver decides when to stop. Closing the shared channel under producers that are still sending
+	// would panic with a plain channel; with safechan they get ErrClosed and return
+
+	shared := safechan.New[int](2)
+	var producers sync.WaitGroup
+
+	for id := 1; id <= 3; id++ {
+		producers.Add(1)
+		go producer(id, shared, &producers)
+	}
+
+	received := 0
+	for range shared.C() { // Values still buffered after Close are received before the loop ends
+		if received++; received == 5 {
+			shared.Close()
+		}
+	}
+	producers.Wait()
+
+	<-shared.Done() // Observers can wait for the close as well
+	fmt.Println("Closed the channel after 5 values; all producers stopped with ErrClosed")
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_sample_examples/018_closing_channel/pubsub"
+)
+
+// drain reads what is left in a subscription until its channel is closed
+func drain(sub *pubsub.Subscription[int]) []int {
+	var got []int
+	for msg := range sub.C() {
+		got = append(got, msg)
+	}
+	return got
+}
+
+func main() {
+
+	// Pub/Sub Hub with Slow-Consumer Policies
+
+	// Closing a channel broadcasts a single signal; the hub broadcasts a stream of messages per topic.
+	// Every subscriber reads from its own buffered channel and chooses what happens when it falls behind
+
+	ctx := context.Background()
+	hub := pubsub.New[int]()
+
+	// Three subscribers that do not read while we publish, each with room for two messages
+	dropOldest, _ := hub.Subscribe("orders", pubsub.WithBuffer(2), pubsub.WithPolicy(pubsub.DropOldest))
+	dropNewest, _ := hub.Subscribe("orders", pubsub.WithBuffer(2), pubsub.WithPolicy(pubsub.DropNewest))
+	disconnect, _ := hub.Subscribe("orders", pubsub.WithBuffer(2), pubsub.WithPolicy(pubsub.Disconnect))
+
+	// A subscriber that reads slowly and makes the publisher wait for it
+	blocking, _ := hub.Subscribe("orders", pubsub.WithBuffer(1), pubsub.WithPolicy(pubsub.Block))
+	blockingDone := make(chan []int)
+	go func() {
+		var got []int
+		for msg := range blocking.C() {
+			time.Sleep(10 * time.Millisecond) // Simulate slow processing
+			got = append(got, msg)
+		}
+		blockingDone <- got
+	}()
+
+	// A subscriber on another topic never sees the orders
+	alerts, _ := hub.Subscribe("alerts")
+
+	for order := 1; order <= 5; order++ {
+		delivered, err := hub.Publish(ctx, "orders", order)
+		fmt.Printf("Published order %d to %d subscribers (err: %v)\n", order, delivered, err)
+	}
+	hub.Publish(ctx, "alerts", 500)
+
+	fmt.Println("-----------------------------------")
+
+	// Unsubscribing closes the channel once the buffered messages have been read
+	blocking.Unsubscribe()
+	fmt.Println("Block:      ", <-blockingDone, "dropped:", blocking.Dropped())
+
+	// The disconnected subscriber's channel was already closed by the hub
+	fmt.Println("Disconnect: ", drain(disconnect), "dropped:", disconnect.Dropped(), "err:", disconnect.Err())
+
+	// Closing the hub closes the remaining subscriptions
+	hub.Close()
+	fmt.Println("Drop oldest:", drain(dropOldest), "dropped:", dropOldest.Dropped(), "err:", dropOldest.Err())
+	fmt.Println("Drop newest:", drain(dropNewest), "dropped:", dropNewest.Dropped(), "err:", dropNewest.Err())
+	fmt.Println("Alerts:     ", drain(alerts), "err:", alerts.Err())
+
+	_, err := hub.Publish(ctx, "orders", 6)
+	fmt.Println("Publish after Close:", err)
+}
@@ -0,0 +1,347 @@
+// Package pubsub is an in-process publish/subscribe hub. It generalizes the
+// closed-channel broadcast from 018_closing_channel to topics with many
+// subscribers, each reading from its own buffered channel. Every subscriber
+// chooses what happens when it falls behind, and its channel is closed
+// exactly once when it unsubscribes, is disconnected or the hub closes.
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrClosed is returned after the hub has been closed, and reported by
+	// Err for the subscriptions it closed.
+	ErrClosed = errors.New("pubsub: hub closed")
+	// ErrSlowConsumer is reported by Err for a subscription that was
+	// disconnected because its buffer was full.
+	ErrSlowConsumer = errors.New("pubsub: slow consumer disconnected")
+)
+
+// Policy decides what a publish does when a subscriber's buffer is full.
+type Policy int
+
+const (
+	// Block waits until the subscriber has room, the subscriber leaves or
+	// the publisher's context is done.
+	Block Policy = iota
+	// DropOldest discards the oldest buffered message to make room. With
+	// WithBuffer(0) there is nothing to discard, so a publish that finds no
+	// waiting reader drops the new message instead.
+	DropOldest
+	// DropNewest discards the message being published.
+	DropNewest
+	// Disconnect unsubscribes the subscriber and closes its channel.
+	Disconnect
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop oldest"
+	case DropNewest:
+		return "drop newest"
+	case Disconnect:
+		return "disconnect"
+	}
+	return "unknown"
+}
+
+type subOptions struct {
+	buffer int
+	policy Policy
+}
+
+// Option configures a subscription.
+type Option func(*subOptions)
+
+// WithBuffer sets the size of the subscriber's channel. The default is 16.
+func WithBuffer(n int) Option {
+	return func(o *subOptions) {
+		o.buffer = max(n, 0)
+	}
+}
+
+// WithPolicy sets the slow-consumer policy. The default is Block.
+func WithPolicy(p Policy) Option {
+	return func(o *subOptions) {
+		o.policy = p
+	}
+}
+
+// Hub routes published messages to the subscribers of a topic.
+type Hub[T any] struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription[T]]struct{}
+	closed bool
+}
+
+// New returns an empty hub.
+func New[T any]() *Hub[T] {
+	return &Hub[T]{topics: make(map[string]map[*Subscription[T]]struct{})}
+}
+
+// Subscribe registers a subscriber for topic.
+func (h *Hub[T]) Subscribe(topic string, opts ...Option) (*Subscription[T], error) {
+	o := subOptions{buffer: 16, policy: Block}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	s := &Subscription[T]{
+		hub:    h,
+		topic:  topic,
+		policy: o.policy,
+		ch:     make(chan T, o.buffer),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	subs := h.topics[topic]
+	if subs == nil {
+		subs = make(map[*Subscription[T]]struct{})
+		h.topics[topic] = subs
+	}
+	subs[s] = struct{}{}
+	return s, nil
+}
+
+// Publish sends msg to every subscriber of topic and returns how many of
+// them received it. Only Block subscribers make it wait, and ctx bounds that
+// wait: the subscribers it gives up on are skipped and ctx's error returned.
+// Every subscriber with room gets msg before Publish waits for any full one,
+// and full ones are waited for side by side, so one slow subscriber does not
+// hold up the others.
+func (h *Hub[T]) Publish(ctx context.Context, topic string, msg T) (int, error) {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return 0, ErrClosed
+	}
+	subs := make([]*Subscription[T], 0, len(h.topics[topic]))
+	for s := range h.topics[topic] {
+		subs = append(subs, s)
+	}
+	h.mu.RUnlock()
+
+	var waiting []*Subscription[T]
+	delivered := 0
+	for _, s := range subs {
+		switch ok, wait := s.offer(msg); {
+		case ok:
+			delivered++
+		case wait:
+			waiting = append(waiting, s)
+		}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, s := range waiting {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := s.wait(ctx, msg)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if ok {
+				delivered++
+			}
+		}()
+	}
+	wg.Wait()
+	return delivered, firstErr
+}
+
+// Subscribers returns the number of subscribers of topic.
+func (h *Hub[T]) Subscribers(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.topics[topic])
+}
+
+// Close closes every subscription and makes later calls fail with ErrClosed.
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	topics := h.topics
+	h.topics = nil
+	h.mu.Unlock()
+
+	for _, subs := range topics {
+		for s := range subs {
+			s.close(ErrClosed)
+		}
+	}
+}
+
+func (h *Hub[T]) remove(s *Subscription[T]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if subs := h.topics[s.topic]; subs != nil {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(h.topics, s.topic)
+		}
+	}
+}
+
+// Subscription is one subscriber of a topic.
+type Subscription[T any] struct {
+	hub    *Hub[T]
+	topic  string
+	policy Policy
+	ch     chan T
+
+	// done is closed first when the subscription ends, to release the
+	// publishers blocked on ch; close waits for them through sending
+	// before closing ch. Blocked publishers do not hold mu.
+	done     chan struct{}
+	doneOnce sync.Once
+	sending  sync.WaitGroup
+	mu       sync.Mutex
+	closed   bool
+	err      error
+
+	dropped atomic.Int64
+}
+
+// C returns the channel the messages arrive on. It is closed when the
+// subscription ends.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Topic returns the subscribed topic.
+func (s *Subscription[T]) Topic() string {
+	return s.topic
+}
+
+// Dropped returns how many messages the slow-consumer policy discarded.
+func (s *Subscription[T]) Dropped() int {
+	return int(s.dropped.Load())
+}
+
+// Err reports why the subscription ended: nil while it is active or after
+// Unsubscribe, ErrSlowConsumer after a disconnect and ErrClosed after the
+// hub was closed.
+func (s *Subscription[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Unsubscribe removes the subscriber and closes its channel. Messages already
+// buffered can still be received. It is safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	s.hub.remove(s)
+	s.close(nil)
+}
+
+func (s *Subscription[T]) close(err error) {
+	s.doneOnce.Do(func() { close(s.done) })
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.err = err
+	s.mu.Unlock()
+
+	// No publisher can start waiting once closed is set, and the waiting
+	// ones give up now that done is closed
+	s.sending.Wait()
+	close(s.ch)
+}
+
+// offer applies the slow-consumer policy without waiting. It reports whether
+// msg was queued, or that the subscriber uses Block and has no room, in which
+// case the caller must call wait.
+func (s *Subscription[T]) offer(msg T) (ok, wait bool) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false, false
+	}
+
+	select {
+	case s.ch <- msg:
+		s.mu.Unlock()
+		return true, false
+	default:
+	}
+
+	switch s.policy {
+	case DropNewest:
+		s.dropped.Add(1)
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- msg:
+				s.mu.Unlock()
+				return true, false
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+				// A reader emptied the buffer, so the next send has
+				// room. An unbuffered channel has nothing to discard:
+				// drop msg rather than spin with the lock held.
+				if cap(s.ch) == 0 {
+					s.dropped.Add(1)
+					s.mu.Unlock()
+					return false, false
+				}
+			}
+		}
+	case Disconnect:
+		s.dropped.Add(1)
+		s.mu.Unlock()
+		s.hub.remove(s)
+		s.close(ErrSlowConsumer)
+		return false, false
+	case Block:
+		s.sending.Add(1) // under mu, so close cannot have started waiting
+		s.mu.Unlock()
+		return false, true
+	}
+	s.mu.Unlock()
+	return false, false
+}
+
+// wait sends msg to a Block subscriber after offer asked for it, until there
+// is room, the subscription ends or ctx is done. The error is only set when
+// ctx is done.
+func (s *Subscription[T]) wait(ctx context.Context, msg T) (bool, error) {
+	defer s.sending.Done()
+	select {
+	case s.ch <- msg:
+		return true, nil
+	case <-s.done:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
@@ -0,0 +1,147 @@
+package pubsub
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// received reads everything left on a closed subscription.
+func received(s *Subscription[int]) []int {
+	var got []int
+	for msg := range s.C() {
+		got = append(got, msg)
+	}
+	return got
+}
+
+func TestPolicies(t *testing.T) {
+	tests := []struct {
+		policy      Policy
+		want        []int
+		wantDropped int
+		wantErr     error
+	}{
+		{DropOldest, []int{4, 5}, 3, ErrClosed},
+		{DropNewest, []int{1, 2}, 3, ErrClosed},
+		{Disconnect, []int{1, 2}, 1, ErrSlowConsumer},
+	}
+	for _, tc := range tests {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			hub := New[int]()
+			s, _ := hub.Subscribe("t", WithBuffer(2), WithPolicy(tc.policy))
+			for msg := 1; msg <= 5; msg++ {
+				hub.Publish(context.Background(), "t", msg)
+			}
+			hub.Close()
+			if got := received(s); !slices.Equal(got, tc.want) {
+				t.Errorf("received %v, want %v", got, tc.want)
+			}
+			if s.Dropped() != tc.wantDropped || s.Err() != tc.wantErr {
+				t.Errorf("Dropped %d and Err %v, want %d and %v", s.Dropped(), s.Err(), tc.wantDropped, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBlockPublishCancelled(t *testing.T) {
+	hub := New[int]()
+	s, _ := hub.Subscribe("t", WithBuffer(1))
+	hub.Publish(context.Background(), "t", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if n, err := hub.Publish(ctx, "t", 2); n != 0 || err != context.DeadlineExceeded {
+		t.Fatalf("Publish to a full Block subscriber = %d, %v, want 0, %v", n, err, context.DeadlineExceeded)
+	}
+	s.Unsubscribe()
+	if got := received(s); !slices.Equal(got, []int{1}) {
+		t.Fatalf("received %v, want [1]", got)
+	}
+}
+
+// TestBlockedPublishHoldsUpNobody checks that while Publish waits for one
+// full Block subscriber, the others still get the message and the waiting
+// subscription can be inspected and ended.
+func TestBlockedPublishHoldsUpNobody(t *testing.T) {
+	hub := New[int]()
+	full1, _ := hub.Subscribe("t", WithBuffer(0))
+	full2, _ := hub.Subscribe("t", WithBuffer(0))
+	others := make([]*Subscription[int], 3)
+	for i := range others {
+		others[i], _ = hub.Subscribe("t", WithBuffer(1))
+	}
+
+	published := make(chan int, 1)
+	go func() {
+		n, _ := hub.Publish(context.Background(), "t", 1)
+		published <- n
+	}()
+
+	for _, s := range others {
+		select {
+		case <-s.C():
+		case <-time.After(time.Second):
+			t.Fatal("a subscriber with room waited for the full Block subscriber")
+		}
+	}
+
+	// Neither of these may wait for the blocked publisher
+	check := make(chan struct{})
+	go func() {
+		full1.Err()
+		hub.Subscribers("t")
+		full1.Unsubscribe()
+		close(check)
+	}()
+	select {
+	case <-check:
+	case <-time.After(time.Second):
+		t.Fatal("Err or Unsubscribe waited for the blocked publisher")
+	}
+
+	// The second full subscriber is waited for on its own
+	if msg := <-full2.C(); msg != 1 {
+		t.Fatalf("full2 received %d, want 1", msg)
+	}
+	if n := <-published; n != 4 {
+		t.Fatalf("Publish delivered to %d subscribers, want 4", n)
+	}
+	if got := received(full1); len(got) != 0 {
+		t.Fatalf("full1 received %v after unsubscribing, want nothing", got)
+	}
+}
+
+func TestClose(t *testing.T) {
+	hub := New[int]()
+	s, _ := hub.Subscribe("t", WithBuffer(0))
+
+	published := make(chan error, 1)
+	go func() {
+		_, err := hub.Publish(context.Background(), "t", 1)
+		published <- err
+	}()
+	time.Sleep(10 * time.Millisecond) // let Publish block
+	hub.Close()
+	hub.Close() // safe to call twice
+
+	// Publish was released by Close, or in a slow run found the hub closed
+	select {
+	case err := <-published:
+		if err != nil && err != ErrClosed {
+			t.Fatalf("Publish = %v, want nil or %v", err, ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not release the blocked publisher")
+	}
+	if _, ok := <-s.C(); ok || s.Err() != ErrClosed {
+		t.Fatalf("channel open %v and Err %v after Close, want closed and %v", ok, s.Err(), ErrClosed)
+	}
+	if _, err := hub.Subscribe("t"); err != ErrClosed {
+		t.Fatalf("Subscribe after Close = %v, want %v", err, ErrClosed)
+	}
+	if _, err := hub.Publish(context.Background(), "t", 2); err != ErrClosed {
+		t.Fatalf("Publish after Close = %v, want %v", err, ErrClosed)
+	}
+}
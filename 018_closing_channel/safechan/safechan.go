@@ -0,0 +1,106 @@
+// Package safechan wraps a channel so that it can be shared by many senders
+// and closed from anywhere. Closing twice is a no-op instead of the
+// "close of closed channel" panic from 05_panic_when_closing_an_already_closed_channel,
+// and sending after close returns an error instead of panicking.
+package safechan
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when sending on a closed channel.
+	ErrClosed = errors.New("safechan: send on closed channel")
+	// ErrFull is returned by TrySend when the channel cannot take a value
+	// without blocking.
+	ErrFull = errors.New("safechan: channel full")
+)
+
+// Chan is a channel with an idempotent Close.
+type Chan[T any] struct {
+	ch   chan T
+	done chan struct{}
+	once sync.Once
+
+	// Senders hold a read lock while sending; Close takes the write lock so
+	// the channel is never closed under a sender.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New returns an open channel with the given buffer size.
+func New[T any](buffer int) *Chan[T] {
+	return &Chan[T]{ch: make(chan T, buffer), done: make(chan struct{})}
+}
+
+// C returns the channel to receive from. It is closed by Close, so receivers
+// can range over it.
+func (c *Chan[T]) C() <-chan T {
+	return c.ch
+}
+
+// Done returns a channel that is closed as soon as Close is called. Senders
+// and other observers can select on it.
+func (c *Chan[T]) Done() <-chan struct{} {
+	return c.done
+}
+
+// Send blocks until v is sent, the channel is closed or ctx is done.
+func (c *Chan[T]) Send(ctx context.Context, v T) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClosed
+	}
+	select {
+	case c.ch <- v:
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TrySend sends v if that does not block. It returns ErrClosed after Close
+// and ErrFull when no receiver or buffer space is available.
+func (c *Chan[T]) TrySend(v T) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClosed
+	}
+	select {
+	case c.ch <- v:
+		return nil
+	default:
+		return ErrFull
+	}
+}
+
+// Close closes the channel and reports whether this call closed it. Calling
+// it again, from any goroutine, does nothing.
+func (c *Chan[T]) Close() bool {
+	closedNow := false
+	c.once.Do(func() {
+		close(c.done) // wakes the blocked senders so they release the lock
+		c.mu.Lock()
+		c.closed = true
+		close(c.ch)
+		c.mu.Unlock()
+		closedNow = true
+	})
+	return closedNow
+}
+
+// Closed reports whether Close has been called.
+func (c *Chan[T]) Closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
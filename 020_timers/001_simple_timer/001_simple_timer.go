@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -10,13 +12,15 @@ func main() {
 	// Basic Example: Simple Timer
 	// Create a basic timer that fires after 2 seconds
 
+	clk := clock.Real()
+
 	// Create a timer that will fire after 2 seconds
-	timer := time.NewTimer(2 * time.Second)
+	timer := clk.NewTimer(2 * time.Second)
 
 	fmt.Println("Waiting for the timer to fire...")
 
 	// Block until the timer's channel sends a value
-	<-timer.C
+	<-timer.C()
 
 	fmt.Println("Timer fired!")
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -11,12 +13,14 @@ func main() {
 	// You can stop a timer before it fires using the Stop method.
 	//If the timer is stopped before it fires, the program will not receive any value from the timer's channel
 
+	clk := clock.Real()
+
 	// Create a timer that will fire after 3 seconds
-	timer := time.NewTimer(3 * time.Second)
+	timer := clk.NewTimer(3 * time.Second)
 
 	// Start a goroutine to stop the timer after 1 second
 	go func() {
-		time.Sleep(1 * time.Second)
+		clk.Sleep(1 * time.Second)
 		stop := timer.Stop()
 		if stop {
 			fmt.Println("Timer stopped before it fired.")
@@ -24,6 +28,6 @@ func main() {
 	}()
 
 	// Wait for the timer to fire
-	<-timer.C
+	<-timer.C()
 	fmt.Println("This will not be printed if the timer is stopped.")
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -11,10 +13,12 @@ func main() {
 	// time.After is a simpler alternative to time.NewTimer for creating a timer that only needs to fire once.
 	// It returns a channel that will receive the current time after the specified duration.
 
+	clk := clock.Real()
+
 	fmt.Println("Waiting for 2 seconds...")
 
 	// Wait for 2 seconds using time.After
-	<-time.After(2 * time.Second)
+	<-clk.After(2 * time.Second)
 
 	fmt.Println("2 seconds passed.")
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -11,15 +13,17 @@ func main() {
 	// You can use timers within a select statement to wait for multiple events simultaneously,
 	// including the timer firing
 
-	timer1 := time.NewTimer(2 * time.Second) // create timer in 2 seconds
-	timer2 := time.NewTimer(4 * time.Second) // create timer in 4 seconds
+	clk := clock.Real()
+
+	timer1 := clk.NewTimer(2 * time.Second) // create timer in 2 seconds
+	timer2 := clk.NewTimer(4 * time.Second) // create timer in 4 seconds
 
 	select {
-	case <-timer1.C:
+	case <-timer1.C():
 		fmt.Println("Timer 1 fired")
-	case <-timer2.C:
+	case <-timer2.C():
 		fmt.Println("Timer 2 fired")
-	case <-time.After(3 * time.Second):
+	case <-clk.After(3 * time.Second):
 		fmt.Println("Timeout! No timer fired in 3 seconds.")
 	}
 
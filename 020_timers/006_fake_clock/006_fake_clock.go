@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+	"go_sample_examples/024_rate_limiter/ratelimit"
+)
+
+func main() {
+
+	// Testing Timers with a Fake Clock
+	// Code written against clock.Clock runs on clock.Real() in production and on a clock.Fake in tests.
+	// The fake clock only moves when Advance is called, so seconds of timers run in microseconds
+
+	start := time.Now()
+	var simulated time.Duration
+
+	// Simple Timer: fires once the clock passes 2 seconds
+	fake := clock.NewFake(time.Time{})
+	timer := fake.NewTimer(2 * time.Second)
+
+	fake.Advance(1999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		fmt.Println("Timer fired too early")
+	default:
+		fmt.Println("Timer has not fired after 1.999s")
+	}
+	fake.Advance(time.Millisecond)
+	fmt.Println("Timer fired at", (<-timer.C()).Format(time.TimeOnly))
+	simulated += 2 * time.Second
+
+	fmt.Println("-----------------------------------")
+
+	// Stopping and Resetting a Timer
+	fake = clock.NewFake(time.Time{})
+	timer = fake.NewTimer(2 * time.Second)
+	fake.Advance(time.Second)
+	fmt.Println("Reset while pending:", timer.Reset(4*time.Second))
+	fake.Advance(3 * time.Second)
+	fmt.Println("Pending timers after 4s:", fake.Pending())
+	fake.Advance(time.Second)
+	fmt.Println("Reset timer fired at", (<-timer.C()).Format(time.TimeOnly))
+
+	timer = fake.NewTimer(time.Second)
+	fmt.Println("Stopped before firing:", timer.Stop())
+	fake.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		fmt.Println("Stopped timer fired")
+	default:
+		fmt.Println("Stopped timer stayed silent for an hour")
+	}
+	simulated += 5*time.Second + time.Hour
+
+	fmt.Println("-----------------------------------")
+
+	// Ticker with Limited Ticks: the test advances one interval at a time
+	fake = clock.NewFake(time.Time{})
+	ticker := fake.NewTicker(time.Second)
+	ticks := make(chan time.Time)
+	go func() {
+		for i := 0; i < 5; i++ {
+			ticks <- <-ticker.C()
+		}
+		ticker.Stop()
+		close(ticks)
+	}()
+	for i := 0; i < 5; i++ {
+		fake.Advance(time.Second)
+		fmt.Println("Tick at", (<-ticks).Format(time.TimeOnly))
+	}
+	<-ticks // The ticker is stopped
+	simulated += 5 * time.Second
+
+	fmt.Println("-----------------------------------")
+
+	// Timeout with select: the worker needs 2s but only gets 1s
+	fake = clock.NewFake(time.Time{})
+	ch := make(chan string)
+	go func() {
+		fake.Sleep(2 * time.Second)
+		ch <- "result"
+	}()
+	timeout := fake.After(time.Second)
+	fake.BlockUntil(2) // The worker is sleeping and the timeout is set
+	fake.Advance(time.Second)
+	select {
+	case res := <-ch:
+		fmt.Println("Received:", res)
+	case <-timeout:
+		fmt.Println("Timeout: No response received")
+	}
+
+	// Timeout with context
+	ctx, cancel := clock.WithTimeout(context.Background(), fake, 2*time.Second)
+	defer cancel()
+	fake.Advance(2 * time.Second) // Also wakes the sleeping worker, whose result nobody reads
+	<-ctx.Done()
+	fmt.Println("Context:", ctx.Err())
+	simulated += 3 * time.Second
+
+	fmt.Println("-----------------------------------")
+
+	// Rate Limiter: Wait blocks until the fake clock refills a token
+	fake = clock.NewFake(time.Time{})
+	limiter := ratelimit.NewTokenBucket(5, 1, ratelimit.WithClock(fake))
+	limiter.Allow()
+
+	waited := make(chan time.Duration)
+	go func() {
+		begin := fake.Now()
+		limiter.Wait(context.Background())
+		waited <- fake.Since(begin)
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(200 * time.Millisecond)
+	fmt.Println("Wait returned after", <-waited, "of fake time")
+	simulated += 200 * time.Millisecond
+
+	fmt.Println("-----------------------------------")
+
+	fmt.Printf("Simulated %v of timers; real time under 100ms: %v\n", simulated, time.Since(start) < 100*time.Millisecond)
+}
@@ -0,0 +1,111 @@
+// Package clock lets code that uses timers, tickers and timeouts run against
+// a clock other than the system one. Code written against Clock uses Real in
+// production and a Fake in tests, where time only moves when the test
+// advances it, so a test of a one-minute timeout runs in microseconds.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the subset of the time package that deals with the passage of
+// time.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a *time.Timer whose channel is returned by C.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a *time.Ticker whose channel is returned by C.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// Real returns the system clock.
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// WithTimeout is context.WithTimeout measured on c.
+func WithTimeout(parent context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	return WithDeadline(parent, c, c.Now().Add(d))
+}
+
+// WithDeadline is context.WithDeadline measured on c. With the real clock it
+// is context.WithDeadline itself.
+func WithDeadline(parent context.Context, c Clock, deadline time.Time) (context.Context, context.CancelFunc) {
+	if _, ok := c.(realClock); ok {
+		return context.WithDeadline(parent, deadline)
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	dctx := deadlineCtx{Context: ctx, deadline: deadline}
+	wait := deadline.Sub(c.Now())
+	if wait <= 0 {
+		// Already expired, like context.WithDeadline with a past deadline
+		cancel(context.DeadlineExceeded)
+		return dctx, func() { cancel(context.Canceled) }
+	}
+	timer := c.AfterFunc(wait, func() { cancel(context.DeadlineExceeded) })
+	return dctx, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// deadlineCtx reports a deadline and context.DeadlineExceeded for a context
+// cancelled by a timer on another clock.
+type deadlineCtx struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c deadlineCtx) Deadline() (time.Time, bool) {
+	if d, ok := c.Context.Deadline(); ok && d.Before(c.deadline) {
+		return d, true
+	}
+	return c.deadline, true
+}
+
+func (c deadlineCtx) Err() error {
+	err := c.Context.Err()
+	if err != nil && context.Cause(c.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+	return err
+}
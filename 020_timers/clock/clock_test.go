@@ -0,0 +1,68 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	f := NewFake(time.Time{})
+	ctx, cancel := WithTimeout(context.Background(), f, time.Minute)
+	defer cancel()
+
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(f.Now().Add(time.Minute)) {
+		t.Fatalf("Deadline = %v, %v, want one minute from now", deadline, ok)
+	}
+	f.Advance(time.Minute - time.Nanosecond)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("Err before the deadline = %v", err)
+	}
+	f.Advance(time.Nanosecond)
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Fatalf("Err at the deadline = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWithDeadlineExpired(t *testing.T) {
+	f := NewFake(time.Time{})
+	for _, d := range []time.Duration{0, -time.Second} {
+		ctx, cancel := WithTimeout(context.Background(), f, d)
+		select {
+		case <-ctx.Done():
+		default:
+			t.Fatalf("WithTimeout(%v) is not done", d)
+		}
+		if err := ctx.Err(); err != context.DeadlineExceeded {
+			t.Fatalf("WithTimeout(%v).Err() = %v, want %v", d, err, context.DeadlineExceeded)
+		}
+		cancel()
+	}
+	if f.Pending() != 0 {
+		t.Fatalf("Pending = %d, want no timers for expired contexts", f.Pending())
+	}
+}
+
+func TestWithDeadlineCancel(t *testing.T) {
+	f := NewFake(time.Time{})
+	ctx, cancel := WithTimeout(context.Background(), f, time.Minute)
+	cancel()
+	if err := ctx.Err(); err != context.Canceled {
+		t.Fatalf("Err after cancel = %v, want %v", err, context.Canceled)
+	}
+	if f.Pending() != 0 {
+		t.Fatalf("Pending = %d after cancel, want 0", f.Pending())
+	}
+}
+
+func TestWithDeadlineParent(t *testing.T) {
+	f := NewFake(time.Time{})
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := WithTimeout(parent, f, time.Minute)
+	defer cancel()
+
+	cancelParent()
+	if err := ctx.Err(); err != context.Canceled {
+		t.Fatalf("Err after the parent was cancelled = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,231 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when Advance or Set is called. Timers,
+// tickers and sleeps fire, in order, as the time they wait for is passed.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer // active timers, not kept in order
+	changed chan struct{}
+}
+
+// NewFake returns a fake clock set to start. A zero start means midnight UTC
+// on 1 January 2024, so the output of programs using it is reproducible.
+func NewFake(start time.Time) *Fake {
+	if start.IsZero() {
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Sleep blocks until the clock has been advanced by d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.NewTimer(d).C()
+}
+
+// After returns a channel that receives the fake time once the clock has
+// been advanced by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer returns a timer that fires once the clock has been advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, ch: make(chan time.Time, 1)}
+	f.schedule(t, d, 0)
+	return t
+}
+
+// NewTicker returns a ticker that ticks every time the clock passes another
+// d. It panics if d is not positive, like time.NewTicker.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{clock: f, ch: make(chan time.Time, 1)}
+	f.schedule(t, d, d)
+	return fakeTicker{t}
+}
+
+// AfterFunc calls fn once the clock has been advanced by d. Unlike
+// time.AfterFunc, fn runs in the goroutine that advanced the clock, before
+// Advance returns.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	t := &fakeTimer{clock: f, fn: fn}
+	f.schedule(t, d, 0)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that come due on
+// the way.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(target)
+}
+
+// Set moves the clock to t, firing the timers that come due on the way. It
+// does nothing if t is not after the current time.
+func (f *Fake) Set(t time.Time) {
+	for {
+		f.mu.Lock()
+		next := f.nextDue(t)
+		if next == nil {
+			if t.After(f.now) {
+				f.now = t
+			}
+			f.mu.Unlock()
+			return
+		}
+
+		f.now = next.when
+		fn := next.fn
+		if next.period > 0 {
+			next.when = next.when.Add(next.period)
+		} else {
+			f.removeLocked(next)
+		}
+		if next.ch != nil {
+			select {
+			case next.ch <- f.now:
+			default: // like a real ticker, drop ticks nobody reads
+			}
+		}
+		f.mu.Unlock()
+
+		if fn != nil {
+			fn()
+		}
+	}
+}
+
+// Pending returns the number of active timers, tickers and sleeps.
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil waits until at least n timers, tickers or sleeps are active.
+// Tests use it to make sure the goroutine under test is waiting before they
+// advance the clock.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.timers) >= n {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+// nextDue returns the earliest timer due at or before t. It must be called
+// with mu held.
+func (f *Fake) nextDue(t time.Time) *fakeTimer {
+	sort.SliceStable(f.timers, func(i, j int) bool { return f.timers[i].when.Before(f.timers[j].when) })
+	if len(f.timers) > 0 && !f.timers[0].when.After(t) {
+		return f.timers[0]
+	}
+	return nil
+}
+
+func (f *Fake) schedule(t *fakeTimer, d, period time.Duration) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	wasActive := f.removeLocked(t)
+	// Like timers since Go 1.23, a stopped or reset timer has no stale value
+	if t.ch != nil {
+		select {
+		case <-t.ch:
+		default:
+		}
+	}
+	t.when = f.now.Add(d)
+	t.period = period
+	f.timers = append(f.timers, t)
+	f.notify()
+	return wasActive
+}
+
+func (f *Fake) stop(t *fakeTimer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.ch != nil {
+		select {
+		case <-t.ch:
+		default:
+		}
+	}
+	return f.removeLocked(t)
+}
+
+// removeLocked must be called with mu held.
+func (f *Fake) removeLocked(t *fakeTimer) bool {
+	for i, other := range f.timers {
+		if other == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.notify()
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// fakeTimer is a timer, or a ticker when period is set.
+type fakeTimer struct {
+	clock  *Fake
+	ch     chan time.Time
+	fn     func()
+	when   time.Time
+	period time.Duration // 0 for timers
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Stop() bool {
+	return t.clock.stop(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	return t.clock.schedule(t, d, 0)
+}
+
+type fakeTicker struct{ *fakeTimer }
+
+func (t fakeTicker) Stop() {
+	t.clock.stop(t.fakeTimer)
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.clock.schedule(t.fakeTimer, d, d)
+}
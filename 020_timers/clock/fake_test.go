@@ -0,0 +1,151 @@
+package clock
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFakeAdvanceFiresInOrder(t *testing.T) {
+	f := NewFake(time.Time{})
+	start := f.Now()
+
+	var fired []time.Duration
+	for _, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second} {
+		f.AfterFunc(d, func() { fired = append(fired, f.Since(start)) })
+	}
+	late := f.NewTimer(time.Hour)
+
+	f.Advance(3 * time.Second)
+	if want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}; !slices.Equal(fired, want) {
+		t.Fatalf("fired at %v, want %v", fired, want)
+	}
+	if got := f.Since(start); got != 3*time.Second {
+		t.Fatalf("clock at %v, want 3s", got)
+	}
+	select {
+	case <-late.C():
+		t.Fatal("a timer fired before it was due")
+	default:
+	}
+	if f.Pending() != 1 {
+		t.Fatalf("Pending = %d, want 1", f.Pending())
+	}
+}
+
+func TestFakeTimerStopAndReset(t *testing.T) {
+	f := NewFake(time.Time{})
+	timer := f.NewTimer(time.Second)
+
+	if !timer.Stop() {
+		t.Fatal("Stop on an active timer returned false")
+	}
+	f.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("a stopped timer fired")
+	default:
+	}
+
+	if timer.Reset(2 * time.Second) {
+		t.Fatal("Reset on a stopped timer returned true")
+	}
+	f.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("a reset timer fired early")
+	default:
+	}
+	f.Advance(time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("a reset timer did not fire")
+	}
+	if timer.Stop() {
+		t.Fatal("Stop on a fired timer returned true")
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(time.Time{})
+	ticker := f.NewTicker(time.Second)
+	start := f.Now()
+
+	for i := 1; i <= 3; i++ {
+		f.Advance(time.Second)
+		select {
+		case tick := <-ticker.C():
+			if got := tick.Sub(start); got != time.Duration(i)*time.Second {
+				t.Fatalf("tick %d at %v, want %ds", i, got, i)
+			}
+		default:
+			t.Fatalf("tick %d missing", i)
+		}
+	}
+
+	// Ticks nobody reads are dropped, like with time.Ticker
+	f.Advance(5 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatal("more than one tick was buffered")
+	default:
+	}
+
+	ticker.Reset(10 * time.Second)
+	f.Advance(5 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("ticked before the reset interval")
+	default:
+	}
+
+	ticker.Stop()
+	f.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Fatal("a stopped ticker ticked")
+	default:
+	}
+	if f.Pending() != 0 {
+		t.Fatalf("Pending = %d after Stop, want 0", f.Pending())
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := NewFake(time.Time{})
+	done := make(chan struct{})
+	go func() {
+		f.Sleep(time.Minute)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(59 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("Sleep returned early")
+	default:
+	}
+	f.Advance(time.Second)
+	<-done
+}
+
+func TestFakeSet(t *testing.T) {
+	f := NewFake(time.Time{})
+	start := f.Now()
+	after := f.After(time.Hour)
+
+	f.Set(start.Add(-time.Hour)) // never moves backwards
+	if !f.Now().Equal(start) {
+		t.Fatalf("Set to the past moved the clock to %v", f.Now())
+	}
+	f.Set(start.Add(2 * time.Hour))
+	if got := (<-after).Sub(start); got != time.Hour {
+		t.Fatalf("After delivered %v, want the time it was due at, 1h", got)
+	}
+	if got := f.Since(start); got != 2*time.Hour {
+		t.Fatalf("clock at %v, want 2h", got)
+	}
+}
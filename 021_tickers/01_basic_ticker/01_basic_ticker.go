@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -13,17 +15,19 @@ func main() {
 	// Simple Ticker
 	// create a ticker that ticks every 1 second
 
+	clk := clock.Real()
+
 	// Create a ticker that ticks every 1 second
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := clk.NewTicker(1 * time.Second)
 
 	// Stop the ticker after 5 ticks
 	go func() {
-		time.Sleep(5 * time.Second)
+		clk.Sleep(5 * time.Second)
 		ticker.Stop()
 	}()
 
 	// Print the tick events
-	for t := range ticker.C {
+	for t := range ticker.C() {
 		fmt.Println("Tick at", t)
 	}
 
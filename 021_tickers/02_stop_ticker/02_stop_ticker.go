@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -10,21 +12,23 @@ func main() {
 	// Stopping a Ticker
 	// Stopping a ticker is crucial when you no longer need it to avoid unnecessary resource consumption
 
+	clk := clock.Real()
+
 	// Create a ticker that ticks every 500 milliseconds
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := clk.NewTicker(500 * time.Millisecond)
 
 	// Create a channel to signal the stop of the ticker
 	done := make(chan bool)
 
 	go func() {
-		time.Sleep(2 * time.Second) // Let the ticker run for 2 seconds
+		clk.Sleep(2 * time.Second) // Let the ticker run for 2 seconds
 		ticker.Stop()
 		done <- true
 	}()
 
 	// Print tick events until the ticker is stopped
 	go func() {
-		for t := range ticker.C {
+		for t := range ticker.C() {
 			fmt.Println("Tick at", t)
 		}
 	}()
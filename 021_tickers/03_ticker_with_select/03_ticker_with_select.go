@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -10,17 +12,19 @@ func main() {
 	// Using Ticker with Select
 	// Use a ticker inside a select statement to manage multiple concurrent events
 
-	ticker := time.NewTicker(1 * time.Second)
+	clk := clock.Real()
+
+	ticker := clk.NewTicker(1 * time.Second)
 	stop := make(chan bool)
 
 	go func() {
-		time.Sleep(3 * time.Second)
+		clk.Sleep(3 * time.Second)
 		stop <- true
 	}()
 
 	for {
 		select {
-		case t := <-ticker.C:
+		case t := <-ticker.C():
 			fmt.Println("Tick at", t)
 		case <-stop:
 			fmt.Println("Stop signal received.")
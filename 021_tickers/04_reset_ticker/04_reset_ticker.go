@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -10,21 +12,23 @@ func main() {
 	// Resetting a Ticker
 	// Resetting a ticker allows you to change the ticker's interval dynamically
 
+	clk := clock.Real()
+
 	// Create a ticker that ticks every 1 second
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := clk.NewTicker(1 * time.Second)
 
 	go func() {
-		for t := range ticker.C {
+		for t := range ticker.C() {
 			fmt.Println("Tick at", t)
 		}
 	}()
 
-	time.Sleep(3 * time.Second)
+	clk.Sleep(3 * time.Second)
 
 	// Reset the ticker to tick every 2 seconds instead of 1 second
 	ticker.Reset(2 * time.Second)
 
-	time.Sleep(6 * time.Second)
+	clk.Sleep(6 * time.Second)
 	ticker.Stop()
 	fmt.Println("Ticker stopped.")
 
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"go_sample_examples/020_timers/clock"
 )
 
 func main() {
@@ -10,14 +12,16 @@ func main() {
 	// Ticker with Limited Ticks
 	// Stop the ticker after a certain number of ticks
 
+	clk := clock.Real()
+
 	// Create a ticker that ticks every 1 second
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := clk.NewTicker(1 * time.Second)
 
 	// Create a counter for the number of ticks
 	count := 0
 	maxTicks := 5
 
-	for t := range ticker.C {
+	for t := range ticker.C() {
 		fmt.Println("Tick at", t)
 		count++
 		if count >= maxTicks {
@@ -1,16 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
+
+	"go_sample_examples/024_rate_limiter/ratelimit"
 )
 
-func rateLimitedWorker(id int, jobs <-chan int, results chan<- int, wg *sync.WaitGroup) {
+func rateLimitedWorker(id int, limiter *ratelimit.TokenBucket, jobs <-chan int, results chan<- int, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for j := range jobs {
-		fmt.Printf("Worker %d processing job %d\n", id, j)
-		time.Sleep(500 * time.Millisecond) // Simulate work and rate limiting
+		// Wait for a token from the limiter shared by all workers
+		if err := limiter.Wait(context.Background()); err != nil {
+			fmt.Printf("Worker %d dropped job %d: %v\n", id, j, err)
+			continue
+		}
+		fmt.Printf("Worker %d processing job %d at %s\n", id, j, time.Now().Format("15:04:05.000"))
 		results <- j * 2
 	}
 }
@@ -23,12 +30,13 @@ func main() {
 	/*
 
 		How Rate Limiting Works Here:
-			Rate Limiting Simulation:
-				-	The time.Sleep(500 * time.Millisecond) introduces a delay of 500 milliseconds before processing the next job.
-				-	This delay is meant to simulate the effect of rate limiting, where each worker can only process one job every 500 milliseconds.
+			Shared Token Bucket:
+				-	All workers draw from a single ratelimit.TokenBucket that refills at 2 tokens per second and holds up to 2 tokens.
+				-	A worker calls limiter.Wait before each job, so the pool as a whole never exceeds the rate, no matter how many workers it has.
 
 			Job Processing:
-				-	Since there are multiple workers (3 in this case), and each job takes 500 milliseconds to process, the overall rate at which jobs are completed will be influenced by the number of workers and the processing time.
+				-	The first 2 jobs run immediately thanks to the burst; after that one job starts every 500 milliseconds.
+				-	Adding more workers does not increase throughput, it only changes which worker picks up the next token.
 
 	*/
 
@@ -36,12 +44,14 @@ func main() {
 	jobs := make(chan int, numJobs)
 	results := make(chan int, numJobs)
 
+	limiter := ratelimit.NewTokenBucket(2, 2)
+
 	var wg sync.WaitGroup
 
 	// Start workers
 	for w := 1; w <= 3; w++ {
 		wg.Add(1)
-		go rateLimitedWorker(w, jobs, results, &wg)
+		go rateLimitedWorker(w, limiter, jobs, results, &wg)
 	}
 
 	// Send jobs to workers
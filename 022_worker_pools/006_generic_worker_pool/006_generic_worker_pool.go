@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_sample_examples/022_worker_pools/workerpool"
+)
+
+type Order struct {
+	ID     int
+	Amount float64
+}
+
+type Invoice struct {
+	OrderID int
+	Total   float64
+}
+
+// Job function to process an order
+func processOrder(ctx context.Context, o Order) (Invoice, error) {
+	select {
+	case <-ctx.Done():
+		return Invoice{}, ctx.Err()
+	case <-time.After(time.Duration(o.ID%3+1) * 100 * time.Millisecond): // Simulate work
+	}
+	if o.Amount <= 0 {
+		return Invoice{}, fmt.Errorf("order %d has an invalid amount", o.ID)
+	}
+	return Invoice{OrderID: o.ID, Total: o.Amount * 1.2}, nil
+}
+
+func main() {
+
+	// Generic Worker Pool
+	// The workerpool package wraps the worker/jobs/results pattern of the previous examples
+	// into a reusable Pool[In, Out] with typed jobs, context cancellation and graceful shutdown
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, err := workerpool.New(ctx, processOrder,
+		workerpool.WithWorkers(3),
+		workerpool.WithOrdering(workerpool.Preserve),
+	)
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+
+	// Send jobs to workers
+	go func() {
+		for id := 1; id <= 6; id++ {
+			order := Order{ID: id, Amount: float64(id * 10)}
+			if id == 4 {
+				order.Amount = 0
+			}
+			if err := pool.Submit(ctx, order); err != nil {
+				fmt.Println("Submit failed:", err)
+				return
+			}
+		}
+
+		// Stop accepting jobs and wait for the queued ones to finish
+		if err := pool.Shutdown(ctx); err != nil {
+			fmt.Println("Shutdown failed:", err)
+		}
+	}()
+
+	// Collect results in submission order
+	for result := range pool.Results() {
+		if result.Err != nil {
+			fmt.Printf("Worker %d: job %d failed with error: %v\n", result.Worker, result.Job.ID, result.Err)
+			continue
+		}
+		fmt.Printf("Worker %d: invoice for order %d is %.2f\n", result.Worker, result.Value.OrderID, result.Value.Total)
+	}
+}
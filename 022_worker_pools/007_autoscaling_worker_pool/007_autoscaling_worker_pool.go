@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_sample_examples/022_worker_pools/workerpool"
+)
+
+func double(ctx context.Context, j int) (int, error) {
+	time.Sleep(200 * time.Millisecond) // Simulate work
+	return j * 2, nil
+}
+
+func main() {
+
+	// Autoscaling Worker Pool
+	// The pool grows and shrinks at runtime between a minimum and a maximum number of workers,
+	// either on request with Resize or automatically with a scaling policy
+
+	ctx := context.Background()
+
+	// Scale on queue depth: one worker for every 4 queued or running jobs
+	pool, err := workerpool.New(ctx, double,
+		workerpool.WithWorkers(1),
+		workerpool.WithQueueSize(20),
+		workerpool.WithScaling(1, 5),
+		workerpool.WithAutoscale(workerpool.QueueDepthPolicy{JobsPerWorker: 4}, 100*time.Millisecond),
+	)
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+
+	// Report the pool size while it runs
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s := pool.Stats()
+				fmt.Printf("Workers: %d, busy: %d, queued: %d\n", s.Workers, s.Busy, s.QueueDepth)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for j := 1; j <= 20; j++ {
+			pool.Submit(ctx, j)
+		}
+		pool.Shutdown(ctx)
+	}()
+
+	count := 0
+	for range pool.Results() {
+		count++
+	}
+	close(stop)
+	fmt.Println("Processed jobs:", count)
+
+	// Manual resizing: the policy is left out and the size is changed by hand
+	manual, err := workerpool.New(ctx, double, workerpool.WithWorkers(2), workerpool.WithScaling(1, 8))
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+	fmt.Println("Initial workers:", manual.Size())
+	fmt.Println("Resized to:", manual.Resize(6))
+	fmt.Println("Resized to:", manual.Resize(20)) // Clamped to the maximum
+	fmt.Println("Resized to:", manual.Resize(0))  // Clamped to the minimum
+	manual.Shutdown(ctx)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_sample_examples/022_worker_pools/workerpool"
+)
+
+type Job struct {
+	ID int
+}
+
+// Job function that fails on even jobs until their third attempt
+func processJob(ctx context.Context, j Job) (string, error) {
+	attempt := workerpool.Attempt(ctx)
+	fmt.Printf("Processing job %d (attempt %d)\n", j.ID, attempt)
+	if j.ID%2 == 0 && attempt < 3 {
+		return "", fmt.Errorf("error processing job %d", j.ID)
+	}
+	if j.ID == 5 {
+		return "", fmt.Errorf("job %d can never succeed", j.ID)
+	}
+	return fmt.Sprintf("job %d done", j.ID), nil
+}
+
+func main() {
+
+	// Worker Pool with Retries and a Dead-Letter Queue
+	// Failed jobs are retried with exponential backoff and jitter, and jobs that run out of
+	// attempts end up in a dead-letter queue that can be inspected or replayed
+
+	ctx := context.Background()
+	deadLetters := &workerpool.DeadLetterQueue[Job, string]{}
+
+	pool, err := workerpool.New(ctx, processJob,
+		workerpool.WithWorkers(3),
+		workerpool.WithQueueSize(5),
+		workerpool.WithRetry(workerpool.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 50 * time.Millisecond,
+			MaxBackoff:     time.Second,
+			Multiplier:     2,
+			Jitter:         0.2,
+		}),
+		workerpool.WithDeadLetter[Job, string](deadLetters),
+	)
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+
+	// Send jobs to workers
+	for j := 1; j <= 5; j++ {
+		pool.Submit(ctx, Job{ID: j})
+	}
+	go pool.Shutdown(ctx)
+
+	// Collect results
+	for result := range pool.Results() {
+		if result.Err != nil {
+			fmt.Printf("Job %d failed after %d attempts: %v\n", result.Job.ID, result.Attempts, result.Err)
+		} else {
+			fmt.Printf("Job %d completed after %d attempts\n", result.Job.ID, result.Attempts)
+		}
+	}
+
+	summary := pool.Summary()
+	fmt.Printf("Succeeded: %d, failed: %d, retries: %d, dead-lettered: %d\n",
+		summary.Succeeded, summary.Failed, summary.Retries, summary.DeadLettered)
+
+	for _, r := range deadLetters.Items() {
+		fmt.Printf("Dead letter: job %d, error: %v\n", r.Job.ID, r.Err)
+	}
+}
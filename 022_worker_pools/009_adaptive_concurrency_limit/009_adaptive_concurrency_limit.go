@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go_sample_examples/022_worker_pools/workerpool"
+)
+
+// downstream simulates a fragile service that slows down when more than 4 calls run at once
+type downstream struct {
+	inFlight atomic.Int32
+}
+
+func (d *downstream) call(ctx context.Context, j int) (int, error) {
+	n := d.inFlight.Add(1)
+	defer d.inFlight.Add(-1)
+
+	latency := 20 * time.Millisecond
+	if n > 4 {
+		latency = time.Duration(n) * 40 * time.Millisecond // Overloaded
+	}
+	time.Sleep(latency)
+	return j * 2, nil
+}
+
+func main() {
+
+	// Adaptive Concurrency Limit
+	// An AIMD limiter in front of the workers raises the number of jobs in flight while latency
+	// stays under the target, and cuts it back as soon as the downstream slows down
+
+	limiter := workerpool.NewAdaptiveLimiter(workerpool.AdaptiveConfig{
+		InitialLimit:  1,
+		MinLimit:      1,
+		MaxLimit:      10,
+		LatencyTarget: 50 * time.Millisecond,
+		MaxErrorRate:  0.1,
+		Window:        5,
+	})
+
+	svc := &downstream{}
+	ctx := context.Background()
+
+	pool, err := workerpool.New(ctx, svc.call,
+		workerpool.WithWorkers(10),
+		workerpool.WithQueueSize(100),
+		workerpool.WithConcurrencyLimiter(limiter),
+	)
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+
+	// Report the limit while the pool runs
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s := limiter.Stats()
+				fmt.Printf("Limit: %d, in flight: %d, last window latency: %v\n",
+					s.Limit, s.InFlight, s.LastLatency.Round(time.Millisecond))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for j := 1; j <= 100; j++ {
+			pool.Submit(ctx, j)
+		}
+		pool.Shutdown(ctx)
+	}()
+
+	for range pool.Results() {
+	}
+	close(done)
+
+	s := limiter.Stats()
+	fmt.Printf("Final limit: %d, increases: %d, decreases: %d\n", s.Limit, s.Increases, s.Decreases)
+}
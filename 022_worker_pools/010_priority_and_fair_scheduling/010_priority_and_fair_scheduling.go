@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_sample_examples/022_worker_pools/workerpool"
+)
+
+type Job struct {
+	Name   string
+	Tenant string
+}
+
+func process(ctx context.Context, j Job) (string, error) {
+	time.Sleep(50 * time.Millisecond) // Simulate work
+	return j.Name, nil
+}
+
+// run submits the jobs through a scheduler and prints the order in which they were processed
+func run(cfg workerpool.SchedulerConfig, submit func(s *workerpool.Scheduler[Job, string])) {
+	ctx := context.Background()
+
+	// A single worker with no queue, so every waiting job stays in the scheduler
+	pool, err := workerpool.New(ctx, process, workerpool.WithWorkers(1), workerpool.WithQueueSize(0))
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+	scheduler := workerpool.NewScheduler(pool, cfg)
+
+	submit(scheduler)
+	go scheduler.Close(ctx)
+
+	for result := range pool.Results() {
+		fmt.Print(result.Value, " ")
+	}
+	fmt.Println()
+
+	stats := scheduler.Stats()
+	fmt.Printf("Dispatched: %d, promoted by the starvation guard: %d\n", stats.Dispatched, stats.Promoted)
+}
+
+func main() {
+
+	// Priority Queues and Weighted Fair Scheduling
+	// A scheduler in front of the pool decides which job the workers get next,
+	// so urgent jobs do not have to wait behind bulk ones in a single FIFO channel
+
+	ctx := context.Background()
+
+	fmt.Println("Strict priority:")
+	run(workerpool.SchedulerConfig{Mode: workerpool.StrictPriority}, func(s *workerpool.Scheduler[Job, string]) {
+		for i := 1; i <= 4; i++ {
+			s.Submit(ctx, Job{Name: fmt.Sprintf("bulk-%d", i)}, 0, "")
+		}
+		s.Submit(ctx, Job{Name: "urgent-1"}, 10, "")
+		s.Submit(ctx, Job{Name: "urgent-2"}, 10, "")
+	})
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+
+	// Tenant "a" has twice the weight of tenant "b", so it gets about two jobs for each of b's
+	fmt.Println("Weighted fair queuing:")
+	run(workerpool.SchedulerConfig{
+		Mode:    workerpool.WeightedFair,
+		Weights: map[string]int{"a": 2, "b": 1},
+	}, func(s *workerpool.Scheduler[Job, string]) {
+		for i := 1; i <= 6; i++ {
+			s.Submit(ctx, Job{Name: fmt.Sprintf("a%d", i), Tenant: "a"}, 0, "a")
+		}
+		for i := 1; i <= 3; i++ {
+			s.Submit(ctx, Job{Name: fmt.Sprintf("b%d", i), Tenant: "b"}, 0, "b")
+		}
+	})
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+
+	// With a steady stream of urgent jobs, the starvation guard still lets the low-priority job through
+	fmt.Println("Strict priority with a starvation guard:")
+	run(workerpool.SchedulerConfig{Mode: workerpool.StrictPriority, MaxWait: 120 * time.Millisecond}, func(s *workerpool.Scheduler[Job, string]) {
+		s.Submit(ctx, Job{Name: "low"}, 0, "")
+		for i := 1; i <= 6; i++ {
+			s.Submit(ctx, Job{Name: fmt.Sprintf("high-%d", i)}, 10, "")
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go_sample_examples/022_worker_pools/jobqueue"
+)
+
+type Result struct {
+	JobID uint64
+	Value int
+}
+
+// Worker function to process jobs, with the same shape as the basic worker pool
+func worker(id int, jobs <-chan jobqueue.Job[int], results chan<- Result, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for j := range jobs {
+		fmt.Printf("Worker %d processing job %d (value %d, attempt %d)\n", id, j.ID, j.Data, j.Attempts)
+		time.Sleep(100 * time.Millisecond) // Simulate work
+		results <- Result{JobID: j.ID, Value: j.Data * 2}
+	}
+}
+
+func main() {
+
+	// Durable Worker Pool
+	// Jobs are stored in an append-only log on disk instead of only in a buffered channel,
+	// so work that was queued or in flight when the process stopped survives a restart
+
+	dir, err := os.MkdirTemp("", "jobqueue")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jobs.log")
+
+	// First run: queue 5 jobs, finish 2 and "crash" while the third is in flight
+	q, err := jobqueue.Open[int](path)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for j := 1; j <= 5; j++ {
+		q.Enqueue(j)
+	}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		job, _ := q.Dequeue(ctx)
+		q.Ack(job.ID)
+	}
+	job, _ := q.Dequeue(ctx)
+	fmt.Printf("Crashing while job %d is in flight\n", job.ID)
+	q.Close()
+
+	// Second run: the in-flight job and the pending ones are recovered
+	q, err = jobqueue.Open[int](path)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer q.Close()
+	pending, _ := q.Len()
+	fmt.Println("Recovered jobs:", pending, "- log records after compaction:", q.LogRecords())
+
+	ctx, cancel := context.WithCancel(ctx)
+	jobs := q.Jobs(ctx)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+
+	// Start workers
+	for w := 1; w <= 2; w++ {
+		wg.Add(1)
+		go worker(w, jobs, results, &wg)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Acknowledge results; stop the workers once the queue is empty
+	for result := range results {
+		q.Ack(result.JobID)
+		fmt.Printf("Job %d acknowledged, result: %d\n", result.JobID, result.Value)
+		if pending, inFlight := q.Len(); pending == 0 && inFlight == 0 {
+			cancel()
+		}
+	}
+
+	fmt.Println("Log records before compaction:", q.LogRecords())
+	q.Compact()
+	fmt.Println("Log records after compaction:", q.LogRecords())
+}
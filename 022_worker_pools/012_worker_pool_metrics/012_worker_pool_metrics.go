@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"go_sample_examples/022_worker_pools/metrics"
+	"go_sample_examples/022_worker_pools/workerpool"
+)
+
+func process(ctx context.Context, j int) (int, error) {
+	time.Sleep(time.Duration(j%4+1) * 20 * time.Millisecond) // Simulate work
+	if j%5 == 0 {
+		return 0, errors.New("simulated failure")
+	}
+	return j * 2, nil
+}
+
+func main() {
+
+	// Worker Pool Metrics
+	// The pool reports queue depth, busy/idle workers, job latency, outcomes and throughput
+	// through the workerpool.Metrics interface, and the registry serves them in the Prometheus text format
+
+	registry := metrics.NewRegistry()
+	poolMetrics := metrics.NewPoolMetrics(registry, "orders")
+
+	ctx := context.Background()
+	pool, err := workerpool.New(ctx, process,
+		workerpool.WithWorkers(3),
+		workerpool.WithQueueSize(20),
+		workerpool.WithMetrics(poolMetrics),
+	)
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+
+	go func() {
+		for j := 1; j <= 20; j++ {
+			pool.Submit(ctx, j)
+		}
+		pool.Shutdown(ctx)
+	}()
+
+	for range pool.Results() {
+	}
+
+	// Scrape the handler in memory, as Prometheus would over HTTP
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	fmt.Println("Content-Type:", rec.Header().Get("Content-Type"))
+	for _, line := range strings.Split(strings.TrimSpace(rec.Body.String()), "\n") {
+		fmt.Println(line)
+	}
+
+	// To expose it for real:
+	// http.Handle("/metrics", registry.Handler())
+	// http.ListenAndServe(":8080", nil)
+}
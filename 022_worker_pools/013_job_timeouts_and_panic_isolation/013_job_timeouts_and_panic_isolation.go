@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go_sample_examples/022_worker_pools/workerpool"
+)
+
+func process(ctx context.Context, j int) (int, error) {
+	switch {
+	case j == 4:
+		var orders map[string]int
+		orders["lost"] = j // Panics: assignment to entry in nil map
+	case j == 7:
+		time.Sleep(time.Hour) // Hangs and ignores ctx
+	case j == 9:
+		select { // Hangs but honours ctx
+		case <-time.After(time.Hour):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	time.Sleep(10 * time.Millisecond) // Simulate work
+	return j * 2, nil
+}
+
+func main() {
+
+	// Per-Job Timeouts and Panic Isolation
+	// Every job runs under its own deadline, a panic becomes a job error carrying the stack trace,
+	// and the worker that hit it is replaced, so one bad job cannot take down or stall the pool
+
+	ctx := context.Background()
+	pool, err := workerpool.New(ctx, process,
+		workerpool.WithWorkers(2),
+		workerpool.WithQueueSize(10),
+		workerpool.WithOrdering(workerpool.Preserve),
+		workerpool.WithJobTimeout(100*time.Millisecond),
+	)
+	if err != nil {
+		fmt.Println("Creating the pool failed:", err)
+		return
+	}
+
+	go func() {
+		for j := 1; j <= 10; j++ {
+			pool.Submit(ctx, j)
+		}
+		pool.Shutdown(ctx)
+	}()
+
+	var stack []byte
+	for r := range pool.Results() {
+		var pe *workerpool.PanicError
+		switch {
+		case errors.As(r.Err, &pe):
+			fmt.Printf("Job %d panicked: %v\n", r.Job, pe.Value)
+			stack = pe.Stack
+		case errors.Is(r.Err, workerpool.ErrJobTimeout):
+			fmt.Printf("Job %d timed out: %v\n", r.Job, r.Err)
+		case r.Err != nil:
+			fmt.Printf("Job %d failed: %v\n", r.Job, r.Err)
+		default:
+			fmt.Printf("Job %d result: %d\n", r.Job, r.Value)
+		}
+	}
+
+	fmt.Println("-----------------------------------")
+
+	// The stack trace points at the line that panicked
+	lines := strings.Split(string(stack), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "main.process(") && i+1 < len(lines) {
+			location, _, _ := strings.Cut(strings.TrimSpace(lines[i+1]), " ")
+			fmt.Println("Panic raised in main.process at", filepath.Base(location))
+			break
+		}
+	}
+
+	s := pool.Summary()
+	fmt.Printf("Succeeded: %d, Failed: %d, Panics: %d, Timeouts: %d\n", s.Succeeded, s.Failed, s.Panics, s.Timeouts)
+}
@@ -0,0 +1,482 @@
+// Package jobqueue is a durable job queue for the worker pools in
+// 022_worker_pools. Jobs are written to an append-only log on local disk
+// before they are handed out, so work that was queued or in flight when the
+// process stopped is delivered again after a restart (at-least-once delivery).
+package jobqueue
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// ErrClosed is returned after the queue has been closed.
+var ErrClosed = errors.New("jobqueue: queue is closed")
+
+// ErrUnknownJob is returned by Ack and Nack for a job that is not in flight.
+var ErrUnknownJob = errors.New("jobqueue: unknown job")
+
+// ErrCorrupt is returned by Open for a log with an unreadable record before
+// its last line. Only the last line can be torn by a crash, so anything else
+// means the file was damaged and is not silently dropped.
+var ErrCorrupt = errors.New("jobqueue: log is corrupt")
+
+// DecodeError is returned by Dequeue for a job whose data cannot be decoded
+// into T, for example after T has changed. The job is removed from the queue
+// so that it does not hold up the jobs behind it; Data is what was enqueued,
+// so the caller can keep it elsewhere.
+type DecodeError struct {
+	ID   uint64
+	Data json.RawMessage
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("jobqueue: decode job %d: %v", e.ID, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Job is a job handed out by the queue. It must be acknowledged with Ack once
+// processed, or returned with Nack.
+type Job[T any] struct {
+	ID       uint64
+	Attempts int // number of times the job has been handed out, including this one
+	Data     T
+}
+
+const (
+	opEnqueue = "enqueue"
+	opDeliver = "deliver"
+	opAck     = "ack"
+	opNack    = "nack"
+)
+
+// record is one line of the log.
+type record struct {
+	Op   string          `json:"op"`
+	ID   uint64          `json:"id"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type entry struct {
+	id       uint64
+	attempts int
+	data     json.RawMessage
+	pos      uint64 // when the job joined the back of the queue, by Enqueue or Nack
+}
+
+func byPos(a, b *entry) int {
+	return cmp.Compare(a.pos, b.pos)
+}
+
+// Queue is a file-backed FIFO queue of jobs of type T. It is safe for
+// concurrent use.
+type Queue[T any] struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	w        *bufio.Writer
+	sync     bool
+	nextID   uint64
+	nextPos  uint64
+	pending  []*entry          // waiting to be handed out, oldest first
+	inFlight map[uint64]*entry // handed out, not yet acknowledged
+	records  int               // records in the log
+	closed   bool
+	ready    chan struct{}
+}
+
+// Option configures a Queue.
+type Option func(*options)
+
+type options struct {
+	noSync bool
+}
+
+// WithoutSync skips the fsync after every write. It is faster, but jobs
+// written just before a machine crash may be lost.
+func WithoutSync() Option {
+	return func(o *options) {
+		o.noSync = true
+	}
+}
+
+// Open opens or creates the queue stored at path. Jobs that were pending or
+// in flight when the queue was last used are queued again, and the log is
+// compacted so completed jobs no longer take space. A torn last record, left
+// by a crash mid-write, is dropped; an unreadable record anywhere else makes
+// Open fail with ErrCorrupt.
+func Open[T any](path string, opts ...Option) (*Queue[T], error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	q := &Queue[T]{
+		path:     path,
+		sync:     !o.noSync,
+		nextID:   1,
+		inFlight: make(map[uint64]*entry),
+		ready:    make(chan struct{}),
+	}
+	if err := q.recover(); err != nil {
+		return nil, err
+	}
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// recover rebuilds the queue from the log.
+func (q *Queue[T]) recover() error {
+	f, err := os.Open(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("jobqueue: open log: %w", err)
+	}
+	defer f.Close()
+
+	entries := make(map[uint64]*entry)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var torn error
+	for line := 1; scanner.Scan(); line++ {
+		if torn != nil {
+			// The unreadable line was not the last one
+			return fmt.Errorf("%w: line %d: %v", ErrCorrupt, line-1, torn)
+		}
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// Tolerated if it turns out to be the last line, torn by a
+			// crash mid-write
+			torn = err
+			continue
+		}
+		switch r.Op {
+		case opEnqueue:
+			entries[r.ID] = &entry{id: r.ID, data: r.Data, pos: q.nextPos}
+			q.nextPos++
+		case opDeliver:
+			if e, ok := entries[r.ID]; ok {
+				e.attempts++
+			}
+		case opAck:
+			delete(entries, r.ID)
+		case opNack:
+			if e, ok := entries[r.ID]; ok {
+				e.pos = q.nextPos
+				q.nextPos++
+			}
+		}
+		q.nextID = max(q.nextID, r.ID+1)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("jobqueue: read log: %w", err)
+	}
+
+	// Pending and in-flight jobs are both handed out again, in the order they
+	// were queued. In-flight jobs were taken from the front, so they come first.
+	q.pending = slices.SortedFunc(maps.Values(entries), byPos)
+	return nil
+}
+
+// compact rewrites the log with only the jobs that are not done yet and
+// reopens it for appending. It must be called with mu held or before the
+// queue is shared.
+func (q *Queue[T]) compact() error {
+	tmp := q.path + ".compact"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("jobqueue: compact: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	records := 0
+
+	write := func(e *entry) error {
+		if err := enc.Encode(record{Op: opEnqueue, ID: e.id, Data: e.data}); err != nil {
+			return err
+		}
+		records++
+		for i := 0; i < e.attempts; i++ {
+			if err := enc.Encode(record{Op: opDeliver, ID: e.id}); err != nil {
+				return err
+			}
+			records++
+		}
+		return nil
+	}
+	// In-flight jobs first, as they were ahead of the pending ones, so that
+	// recover queues them in the same order
+	inFlight := slices.SortedFunc(maps.Values(q.inFlight), byPos)
+	for _, e := range append(inFlight, q.pending...) {
+		if err = write(e); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, q.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("jobqueue: compact: %w", err)
+	}
+	syncDir(filepath.Dir(q.path))
+
+	if q.file != nil {
+		q.file.Close()
+	}
+	q.file, err = os.OpenFile(q.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("jobqueue: reopen log: %w", err)
+	}
+	q.w = bufio.NewWriter(q.file)
+	q.records = records
+	return nil
+}
+
+// syncDir makes a rename durable. Errors are ignored because not every
+// platform supports syncing a directory.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// append writes records to the log and syncs it. It must be called with mu
+// held.
+func (q *Queue[T]) append(records ...record) error {
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		q.w.Write(line)
+		q.w.WriteByte('\n')
+	}
+	if err := q.w.Flush(); err != nil {
+		return fmt.Errorf("jobqueue: write log: %w", err)
+	}
+	q.records += len(records)
+	if q.sync {
+		if err := q.file.Sync(); err != nil {
+			return fmt.Errorf("jobqueue: sync log: %w", err)
+		}
+	}
+	return nil
+}
+
+// wake tells waiting Dequeue calls that something changed. It must be called
+// with mu held.
+func (q *Queue[T]) wake() {
+	close(q.ready)
+	q.ready = make(chan struct{})
+}
+
+// Enqueue durably adds a job and returns its id.
+func (q *Queue[T]) Enqueue(data T) (uint64, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return 0, fmt.Errorf("jobqueue: encode job: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return 0, ErrClosed
+	}
+	e := &entry{id: q.nextID, data: raw, pos: q.nextPos}
+	if err := q.append(record{Op: opEnqueue, ID: e.id, Data: raw}); err != nil {
+		return 0, err
+	}
+	q.nextID++
+	q.nextPos++
+	q.pending = append(q.pending, e)
+	q.wake()
+	return e.id, nil
+}
+
+// Dequeue hands out the oldest pending job, waiting until one is available,
+// ctx is done or the queue is closed. A job that cannot be decoded is dropped
+// and reported with a *DecodeError.
+func (q *Queue[T]) Dequeue(ctx context.Context) (Job[T], error) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return Job[T]{}, ErrClosed
+		}
+		if len(q.pending) > 0 {
+			job, err := q.deliver()
+			q.mu.Unlock()
+			return job, err
+		}
+		ready := q.ready
+		q.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return Job[T]{}, ctx.Err()
+		}
+	}
+}
+
+// deliver moves the oldest pending job in flight. It must be called with mu
+// held.
+func (q *Queue[T]) deliver() (Job[T], error) {
+	e := q.pending[0]
+	var data T
+	if err := json.Unmarshal(e.data, &data); err != nil {
+		// Drop it, or it would be at the front of the queue for good. If
+		// the ack cannot be written, the job comes back after a restart.
+		q.pending[0] = nil
+		q.pending = q.pending[1:]
+		if err := q.append(record{Op: opAck, ID: e.id}); err != nil {
+			return Job[T]{}, err
+		}
+		return Job[T]{}, &DecodeError{ID: e.id, Data: e.data, Err: err}
+	}
+	if err := q.append(record{Op: opDeliver, ID: e.id}); err != nil {
+		return Job[T]{}, err
+	}
+	q.pending[0] = nil
+	q.pending = q.pending[1:]
+	e.attempts++
+	q.inFlight[e.id] = e
+	return Job[T]{ID: e.id, Attempts: e.attempts, Data: data}, nil
+}
+
+// Ack marks a job as done. It is not handed out again.
+func (q *Queue[T]) Ack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	if _, ok := q.inFlight[id]; !ok {
+		return ErrUnknownJob
+	}
+	if err := q.append(record{Op: opAck, ID: id}); err != nil {
+		return err
+	}
+	delete(q.inFlight, id)
+	return nil
+}
+
+// Nack returns a job to the back of the queue so it is handed out again.
+func (q *Queue[T]) Nack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	e, ok := q.inFlight[id]
+	if !ok {
+		return ErrUnknownJob
+	}
+	if err := q.append(record{Op: opNack, ID: id}); err != nil {
+		return err
+	}
+	delete(q.inFlight, id)
+	e.pos = q.nextPos
+	q.nextPos++
+	q.pending = append(q.pending, e)
+	q.wake()
+	return nil
+}
+
+// Jobs returns a channel that receives jobs as they become available, so the
+// queue can feed the worker(id, jobs, results, wg) functions of the worker pool
+// examples. The channel is closed when ctx is done or the queue is closed.
+// Jobs that cannot be decoded are skipped; Dequeue reports them.
+func (q *Queue[T]) Jobs(ctx context.Context) <-chan Job[T] {
+	jobs := make(chan Job[T])
+	go func() {
+		defer close(jobs)
+		for {
+			job, err := q.Dequeue(ctx)
+			var decodeErr *DecodeError
+			if errors.As(err, &decodeErr) {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				// Hand the job back so it is not stuck in flight
+				q.Nack(job.ID)
+				return
+			}
+		}
+	}()
+	return jobs
+}
+
+// Len returns the number of pending and in-flight jobs.
+func (q *Queue[T]) Len() (pending, inFlight int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending), len(q.inFlight)
+}
+
+// Compact rewrites the log without the records of completed jobs.
+func (q *Queue[T]) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	return q.compact()
+}
+
+// LogRecords returns the number of records in the log, which grows until the
+// next compaction.
+func (q *Queue[T]) LogRecords() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.records
+}
+
+// Close flushes and closes the log. Jobs still in flight are handed out again
+// the next time the queue is opened.
+func (q *Queue[T]) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	q.wake()
+	if err := q.w.Flush(); err != nil {
+		q.file.Close()
+		return err
+	}
+	return q.file.Close()
+}
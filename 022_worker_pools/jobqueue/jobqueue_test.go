@@ -0,0 +1,238 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func openQueue(t *testing.T, path string) *Queue[string] {
+	t.Helper()
+	q, err := Open[string](path, WithoutSync())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+	return q
+}
+
+func dequeue(t *testing.T, q *Queue[string]) Job[string] {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	job, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+	return job
+}
+
+// drain dequeues every pending job and returns their data in order.
+func drain(t *testing.T, q *Queue[string]) []string {
+	t.Helper()
+	var got []string
+	for {
+		if pending, _ := q.Len(); pending == 0 {
+			return got
+		}
+		got = append(got, dequeue(t, q).Data)
+	}
+}
+
+func enqueue(t *testing.T, q *Queue[string], jobs ...string) {
+	t.Helper()
+	for _, j := range jobs {
+		if _, err := q.Enqueue(j); err != nil {
+			t.Fatalf("Enqueue(%s): %v", j, err)
+		}
+	}
+}
+
+func TestQueue(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, q *Queue[string])
+		want []string // jobs handed out after the queue is reopened
+	}{
+		{
+			"pending jobs survive",
+			func(t *testing.T, q *Queue[string]) { enqueue(t, q, "a", "b", "c") },
+			[]string{"a", "b", "c"},
+		},
+		{
+			"acked jobs are gone",
+			func(t *testing.T, q *Queue[string]) {
+				enqueue(t, q, "a", "b", "c")
+				q.Ack(dequeue(t, q).ID)
+			},
+			[]string{"b", "c"},
+		},
+		{
+			"in-flight jobs come back first",
+			func(t *testing.T, q *Queue[string]) {
+				enqueue(t, q, "a", "b", "c")
+				dequeue(t, q)
+				dequeue(t, q)
+			},
+			[]string{"a", "b", "c"},
+		},
+		{
+			"nacked jobs go to the back",
+			func(t *testing.T, q *Queue[string]) {
+				enqueue(t, q, "a", "b", "c")
+				q.Nack(dequeue(t, q).ID)
+			},
+			[]string{"b", "c", "a"},
+		},
+		{
+			"nacked and redelivered jobs keep their order",
+			func(t *testing.T, q *Queue[string]) {
+				enqueue(t, q, "a", "b")
+				q.Nack(dequeue(t, q).ID) // b, a
+				dequeue(t, q)            // b in flight
+				dequeue(t, q)            // a in flight
+			},
+			[]string{"b", "a"},
+		},
+	}
+	for _, tc := range tests {
+		for _, compact := range []bool{false, true} {
+			name := tc.name
+			if compact {
+				name += " compacted"
+			}
+			t.Run(name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "jobs.log")
+				q := openQueue(t, path)
+				tc.run(t, q)
+
+				// The queue hands jobs out in the same order before and
+				// after a restart, with or without a compaction in between
+				if compact {
+					if err := q.Compact(); err != nil {
+						t.Fatalf("Compact: %v", err)
+					}
+				}
+				q.Close()
+
+				q = openQueue(t, path)
+				if got := drain(t, q); !slices.Equal(got, tc.want) {
+					t.Fatalf("after reopening got %v, want %v", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestAttemptsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	q := openQueue(t, path)
+	enqueue(t, q, "a")
+	q.Nack(dequeue(t, q).ID)
+	dequeue(t, q)
+	q.Close()
+
+	q = openQueue(t, path)
+	if job := dequeue(t, q); job.Attempts != 3 {
+		t.Fatalf("Attempts = %d on the third delivery, want 3", job.Attempts)
+	}
+}
+
+func TestAckAndNackErrors(t *testing.T) {
+	q := openQueue(t, filepath.Join(t.TempDir(), "jobs.log"))
+	enqueue(t, q, "a")
+	id := dequeue(t, q).ID
+	if err := q.Ack(id); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if err := q.Ack(id); err != ErrUnknownJob {
+		t.Fatalf("second Ack = %v, want %v", err, ErrUnknownJob)
+	}
+	if err := q.Nack(id); err != ErrUnknownJob {
+		t.Fatalf("Nack after Ack = %v, want %v", err, ErrUnknownJob)
+	}
+	q.Close()
+	if _, err := q.Enqueue("b"); err != ErrClosed {
+		t.Fatalf("Enqueue after Close = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestCompactShrinksLog(t *testing.T) {
+	q := openQueue(t, filepath.Join(t.TempDir(), "jobs.log"))
+	enqueue(t, q, "a", "b", "c")
+	q.Ack(dequeue(t, q).ID)
+	q.Ack(dequeue(t, q).ID)
+	if got := q.LogRecords(); got != 7 {
+		t.Fatalf("LogRecords = %d before compaction, want 7", got)
+	}
+	if err := q.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if got := q.LogRecords(); got != 1 {
+		t.Fatalf("LogRecords = %d after compaction, want 1", got)
+	}
+	if got := drain(t, q); !slices.Equal(got, []string{"c"}) {
+		t.Fatalf("got %v after compaction, want [c]", got)
+	}
+}
+
+func TestRecoverDamagedLog(t *testing.T) {
+	const valid = `{"op":"enqueue","id":1,"data":"a"}` + "\n" +
+		`{"op":"enqueue","id":2,"data":"b"}` + "\n"
+	tests := []struct {
+		name    string
+		log     string
+		want    []string
+		wantErr error
+	}{
+		{"torn last line", valid + `{"op":"enq`, []string{"a", "b"}, nil},
+		{"torn last line with newline", valid + `{"op":"enq` + "\n", []string{"a", "b"}, nil},
+		{"bad line in the middle", `{"op":"enqueue","id":1,"data":"a"}` + "\ngarbage\n" + `{"op":"enqueue","id":2,"data":"b"}` + "\n", nil, ErrCorrupt},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "jobs.log")
+			if err := os.WriteFile(path, []byte(tc.log), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			q, err := Open[string](path, WithoutSync())
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Open = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer q.Close()
+			if got := drain(t, q); !slices.Equal(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			if id, _ := q.Enqueue("c"); id != 3 {
+				t.Fatalf("next id = %d, want 3", id)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	ints, err := Open[int](path, WithoutSync())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	ints.Enqueue(1)
+	ints.Close()
+
+	q := openQueue(t, path)
+	_, err = q.Dequeue(context.Background())
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || string(decodeErr.Data) != "1" {
+		t.Fatalf("Dequeue = %v, want a DecodeError with the data", err)
+	}
+	if pending, inFlight := q.Len(); pending+inFlight != 0 {
+		t.Fatalf("%d pending and %d in flight after a decode error, want none", pending, inFlight)
+	}
+}
@@ -0,0 +1,266 @@
+// Package metrics is a small metrics registry with a Prometheus text
+// exposition handler. It has no dependencies outside the standard library, so
+// the worker pools can be scraped or inspected without any external service.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Labels are constant label pairs attached to a series.
+type Labels map[string]string
+
+// The text exposition format escapes only these characters, unlike Go's %q,
+// which would also escape tabs and non-ASCII runes. HELP text keeps its
+// double quotes.
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + `="` + labelEscaper.Replace(l[k]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// with returns a copy of l with one more pair.
+func (l Labels) with(key, value string) Labels {
+	out := make(Labels, len(l)+1)
+	for k, v := range l {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+type series interface {
+	write(w io.Writer, name string)
+}
+
+type family struct {
+	name, help, kind string
+	series           []series
+}
+
+// Registry holds metrics and writes them in the Prometheus text format.
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(name, help, kind string, s series) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range r.families {
+		if f.name == name {
+			if f.kind != kind {
+				panic(fmt.Sprintf("metrics: %s registered as %s and %s", name, f.kind, kind))
+			}
+			f.series = append(f.series, s)
+			return
+		}
+	}
+	r.families = append(r.families, &family{name: name, help: help, kind: kind, series: []series{s}})
+}
+
+// WriteText writes every metric in the Prometheus text exposition format.
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.Lock()
+	families := slices.Clone(r.families)
+	r.mu.Unlock()
+
+	for _, f := range families {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, helpEscaper.Replace(f.help))
+		fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.series {
+			s.write(w, f.name)
+		}
+	}
+}
+
+// Handler serves the registry in the Prometheus text exposition format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// atomicFloat is a float64 updated with compare-and-swap.
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+func (f *atomicFloat) Store(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// Counter is a value that only goes up.
+type Counter struct {
+	labels Labels
+	value  atomicFloat
+}
+
+// NewCounter registers a counter.
+func (r *Registry) NewCounter(name, help string, labels Labels) *Counter {
+	c := &Counter{labels: labels}
+	r.register(name, help, "counter", c)
+	return c
+}
+
+// Inc adds one.
+func (c *Counter) Inc() { c.value.Add(1) }
+
+// Add adds v, which must not be negative.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.value.Add(v)
+}
+
+// Value returns the current value.
+func (c *Counter) Value() float64 { return c.value.Load() }
+
+func (c *Counter) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s%s %s\n", name, c.labels, formatFloat(c.Value()))
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	labels Labels
+	value  atomicFloat
+}
+
+// NewGauge registers a gauge.
+func (r *Registry) NewGauge(name, help string, labels Labels) *Gauge {
+	g := &Gauge{labels: labels}
+	r.register(name, help, "gauge", g)
+	return g
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) { g.value.Store(v) }
+
+// Add adds v, which may be negative.
+func (g *Gauge) Add(v float64) { g.value.Add(v) }
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 { return g.value.Load() }
+
+func (g *Gauge) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s%s %s\n", name, g.labels, formatFloat(g.Value()))
+}
+
+type gaugeFunc struct {
+	labels Labels
+	fn     func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is computed by fn at scrape
+// time.
+func (r *Registry) NewGaugeFunc(name, help string, labels Labels, fn func() float64) {
+	r.register(name, help, "gauge", &gaugeFunc{labels: labels, fn: fn})
+}
+
+func (g *gaugeFunc) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s%s %s\n", name, g.labels, formatFloat(g.fn()))
+}
+
+// DefaultBuckets are histogram bounds in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	labels  Labels
+	mu      sync.Mutex
+	bounds  []float64
+	counts  []uint64
+	sum     float64
+	samples uint64
+}
+
+// NewHistogram registers a histogram with the given upper bounds, or
+// DefaultBuckets when bounds is nil.
+func (r *Registry) NewHistogram(name, help string, labels Labels, bounds []float64) *Histogram {
+	if bounds == nil {
+		bounds = DefaultBuckets
+	}
+	bounds = slices.Clone(bounds)
+	slices.Sort(bounds)
+	h := &Histogram{labels: labels, bounds: bounds, counts: make([]uint64, len(bounds))}
+	r.register(name, help, "histogram", h)
+	return h
+}
+
+// Observe records v.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i, _ := slices.BinarySearch(h.bounds, v); i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.samples++
+}
+
+func (h *Histogram) write(w io.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, h.labels.with("le", formatFloat(bound)), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, h.labels.with("le", "+Inf"), h.samples)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, h.labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, h.labels, h.samples)
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// golden is the exposition text of the registry built in TestWriteText.
+const golden = `# HELP jobs_total Jobs processed, by "status".
+# TYPE jobs_total counter
+jobs_total{path="C:\\jobs",pool="a\"b\nc",status="ok"} 3
+jobs_total{status="tab	and ünïcode"} 0
+# HELP queue_depth Jobs waiting.\nSampled at scrape time, see C:\\docs.
+# TYPE queue_depth gauge
+queue_depth -1.5
+# HELP workers Workers running.
+# TYPE workers gauge
+workers 4
+# HELP latency_seconds Job latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1",pool="a"} 1
+latency_seconds_bucket{le="1",pool="a"} 2
+latency_seconds_bucket{le="+Inf",pool="a"} 3
+latency_seconds_sum{pool="a"} 5.55
+latency_seconds_count{pool="a"} 3
+`
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	ok := r.NewCounter("jobs_total", `Jobs processed, by "status".`, Labels{"status": "ok", "pool": "a\"b\nc", "path": `C:\jobs`})
+	ok.Inc()
+	ok.Add(2)
+	r.NewCounter("jobs_total", "ignored, the first HELP wins", Labels{"status": "tab\tand ünïcode"})
+
+	depth := r.NewGauge("queue_depth", "Jobs waiting.\nSampled at scrape time, see C:\\docs.", nil)
+	depth.Set(1)
+	depth.Add(-2.5)
+	r.NewGaugeFunc("workers", "Workers running.", nil, func() float64 { return 4 })
+
+	h := r.NewHistogram("latency_seconds", "Job latency.", Labels{"pool": "a"}, []float64{1, 0.1})
+	for _, v := range []float64{0.05, 0.5, 5} {
+		h.Observe(v)
+	}
+
+	var b strings.Builder
+	r.WriteText(&b)
+	if got := b.String(); got != golden {
+		t.Fatalf("exposition text differs\ngot:\n%s\nwant:\n%s", got, golden)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("requests_total", "Requests.", nil).Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "requests_total 1\n") {
+		t.Errorf("body %q does not contain the counter", rec.Body.String())
+	}
+}
+
+func TestRegisterKindMismatchPanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("x", "", nil)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering x as a gauge did not panic")
+		}
+	}()
+	r.NewGauge("x", "", nil)
+}
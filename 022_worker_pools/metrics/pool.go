@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// PoolMetrics records worker pool activity in a Registry. It implements the
+// workerpool.Metrics interface; pass it to workerpool.WithMetrics.
+type PoolMetrics struct {
+	queueDepth *Gauge
+	busy       *Gauge
+	idle       *Gauge
+	succeeded  *Counter
+	failed     *Counter
+	latency    *Histogram
+	throughput rateTracker
+}
+
+// NewPoolMetrics registers the metrics of one pool. The pool label tells
+// pools apart when several share a registry.
+func NewPoolMetrics(r *Registry, pool string) *PoolMetrics {
+	labels := Labels{"pool": pool}
+	m := &PoolMetrics{
+		queueDepth: r.NewGauge("workerpool_queue_depth", "Jobs waiting in the queue.", labels),
+		busy:       r.NewGauge("workerpool_workers", "Workers by state.", labels.with("state", "busy")),
+		idle:       r.NewGauge("workerpool_workers", "Workers by state.", labels.with("state", "idle")),
+		succeeded:  r.NewCounter("workerpool_jobs_total", "Jobs processed by outcome.", labels.with("outcome", "success")),
+		failed:     r.NewCounter("workerpool_jobs_total", "Jobs processed by outcome.", labels.with("outcome", "failure")),
+		latency:    r.NewHistogram("workerpool_job_duration_seconds", "Time spent processing a job.", labels, nil),
+	}
+	r.NewGaugeFunc("workerpool_throughput_jobs_per_second", "Jobs finished per second over the last 10 seconds.", labels, m.Throughput)
+	return m
+}
+
+// QueueDepth implements workerpool.Metrics.
+func (m *PoolMetrics) QueueDepth(n int) {
+	m.queueDepth.Set(float64(n))
+}
+
+// Workers implements workerpool.Metrics.
+func (m *PoolMetrics) Workers(busy, idle int) {
+	m.busy.Set(float64(busy))
+	m.idle.Set(float64(idle))
+}
+
+// JobDone implements workerpool.Metrics.
+func (m *PoolMetrics) JobDone(latency time.Duration, err error) {
+	if err != nil {
+		m.failed.Inc()
+	} else {
+		m.succeeded.Inc()
+	}
+	m.latency.Observe(latency.Seconds())
+	m.throughput.add(time.Now())
+}
+
+// Throughput returns the jobs finished per second over the last 10 seconds.
+func (m *PoolMetrics) Throughput() float64 {
+	return m.throughput.rate(time.Now())
+}
+
+const rateWindow = 10 // seconds
+
+// rateTracker counts events in one-second buckets over a sliding window.
+type rateTracker struct {
+	mu      sync.Mutex
+	buckets [rateWindow]int
+	seconds [rateWindow]int64 // the second each bucket belongs to
+}
+
+func (t *rateTracker) add(now time.Time) {
+	sec := now.Unix()
+	i := sec % rateWindow
+	t.mu.Lock()
+	if t.seconds[i] != sec {
+		t.seconds[i], t.buckets[i] = sec, 0
+	}
+	t.buckets[i]++
+	t.mu.Unlock()
+}
+
+func (t *rateTracker) rate(now time.Time) float64 {
+	sec := now.Unix()
+	total := 0
+	t.mu.Lock()
+	for i := range t.buckets {
+		if sec-t.seconds[i] < rateWindow {
+			total += t.buckets[i]
+		}
+	}
+	t.mu.Unlock()
+	return float64(total) / rateWindow
+}
@@ -0,0 +1,158 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AdaptiveConfig configures an AdaptiveLimiter.
+type AdaptiveConfig struct {
+	InitialLimit  int           // starting number of jobs allowed in flight
+	MinLimit      int           // lower bound for the limit; defaults to 1
+	MaxLimit      int           // upper bound for the limit; defaults to 100
+	LatencyTarget time.Duration // average latency above this counts as overload
+	MaxErrorRate  float64       // error rate above this counts as overload; 0 disables the check
+	Decrease      float64       // factor applied to the limit on overload; defaults to 0.75
+	Window        int           // completed jobs per adjustment; defaults to 10
+}
+
+// AdaptiveStats describes the state of an AdaptiveLimiter.
+type AdaptiveStats struct {
+	Limit       int
+	InFlight    int
+	LastLatency time.Duration // average latency of the last full window
+	LastErrors  float64       // error rate of the last full window
+	Increases   int
+	Decreases   int
+}
+
+// AdaptiveLimiter limits the number of jobs in flight and adjusts the limit
+// with AIMD (additive increase, multiplicative decrease): while latency and
+// errors stay healthy the limit grows by one per window, and when they rise
+// it is cut by the Decrease factor.
+type AdaptiveLimiter struct {
+	mu       sync.Mutex
+	cfg      AdaptiveConfig
+	limit    int
+	inFlight int
+	changed  chan struct{}
+
+	// current window
+	samples   int
+	errors    int
+	latency   time.Duration
+	saturated bool
+
+	stats AdaptiveStats
+}
+
+// NewAdaptiveLimiter returns a limiter configured by cfg.
+func NewAdaptiveLimiter(cfg AdaptiveConfig) *AdaptiveLimiter {
+	cfg.MinLimit = max(cfg.MinLimit, 1)
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = 100
+	}
+	cfg.MaxLimit = max(cfg.MaxLimit, cfg.MinLimit)
+	if cfg.Decrease <= 0 || cfg.Decrease >= 1 {
+		cfg.Decrease = 0.75
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 10
+	}
+	limit := max(cfg.MinLimit, min(cfg.InitialLimit, cfg.MaxLimit))
+	return &AdaptiveLimiter{cfg: cfg, limit: limit, changed: make(chan struct{})}
+}
+
+// Acquire waits until a job may start. The returned release function must be
+// called when the job is done, with the job's error.
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) (release func(err error), err error) {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			if l.inFlight == l.limit {
+				l.saturated = true
+			}
+			l.mu.Unlock()
+			start := time.Now()
+			return func(err error) { l.release(time.Since(start), err) }, nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.samples++
+	l.latency += latency
+	if err != nil {
+		l.errors++
+	}
+	if l.samples >= l.cfg.Window {
+		l.adjust()
+	}
+
+	// Wake up the goroutines waiting in Acquire
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// adjust applies AIMD at the end of a window. It must be called with mu held.
+func (l *AdaptiveLimiter) adjust() {
+	avg := l.latency / time.Duration(l.samples)
+	errorRate := float64(l.errors) / float64(l.samples)
+	l.stats.LastLatency = avg
+	l.stats.LastErrors = errorRate
+
+	overloaded := (l.cfg.LatencyTarget > 0 && avg > l.cfg.LatencyTarget) ||
+		(l.cfg.MaxErrorRate > 0 && errorRate > l.cfg.MaxErrorRate)
+
+	switch {
+	case overloaded:
+		l.limit = max(l.cfg.MinLimit, int(float64(l.limit)*l.cfg.Decrease))
+		l.stats.Decreases++
+	case l.saturated && l.limit < l.cfg.MaxLimit:
+		// Only grow when the current limit is actually being used
+		l.limit++
+		l.stats.Increases++
+	}
+
+	l.samples, l.errors, l.latency, l.saturated = 0, 0, 0, l.inFlight >= l.limit
+}
+
+// Limit returns the current limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Stats returns the current limit and latency statistics.
+func (l *AdaptiveLimiter) Stats() AdaptiveStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stats
+	s.Limit = l.limit
+	s.InFlight = l.inFlight
+	return s
+}
+
+// WithConcurrencyLimiter makes every worker acquire l before running a job,
+// so the number of jobs in flight follows the adaptive limit rather than the
+// number of workers.
+func WithConcurrencyLimiter(l *AdaptiveLimiter) Option {
+	return func(c *config) {
+		c.limiter = l
+	}
+}
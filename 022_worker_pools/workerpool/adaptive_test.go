@@ -0,0 +1,163 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewAdaptiveLimiterDefaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       AdaptiveConfig
+		wantLimit int
+		wantMin   int
+		wantMax   int
+	}{
+		{"zero config", AdaptiveConfig{}, 1, 1, 100},
+		{"initial within bounds", AdaptiveConfig{InitialLimit: 5, MinLimit: 2, MaxLimit: 10}, 5, 2, 10},
+		{"initial above max", AdaptiveConfig{InitialLimit: 50, MaxLimit: 10}, 10, 1, 10},
+		{"max below min", AdaptiveConfig{MinLimit: 8, MaxLimit: 4}, 8, 8, 8},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewAdaptiveLimiter(tc.cfg)
+			if l.Limit() != tc.wantLimit || l.cfg.MinLimit != tc.wantMin || l.cfg.MaxLimit != tc.wantMax {
+				t.Fatalf("limit %d in [%d, %d], want %d in [%d, %d]",
+					l.Limit(), l.cfg.MinLimit, l.cfg.MaxLimit, tc.wantLimit, tc.wantMin, tc.wantMax)
+			}
+			if l.cfg.Decrease != 0.75 || l.cfg.Window != 10 {
+				t.Fatalf("Decrease %v and Window %d, want 0.75 and 10", l.cfg.Decrease, l.cfg.Window)
+			}
+		})
+	}
+}
+
+// fillWindow completes one window of jobs with the given latency and error,
+// keeping the limit saturated if saturate is set.
+func fillWindow(t *testing.T, l *AdaptiveLimiter, latency time.Duration, err error, saturate bool) {
+	t.Helper()
+	for range l.cfg.Window {
+		if saturate {
+			for l.Stats().InFlight < l.Limit() {
+				if _, aerr := l.Acquire(context.Background()); aerr != nil {
+					t.Fatalf("Acquire: %v", aerr)
+				}
+			}
+		} else if _, aerr := l.Acquire(context.Background()); aerr != nil {
+			t.Fatalf("Acquire: %v", aerr)
+		}
+		l.release(latency, err)
+	}
+}
+
+func TestAdaptiveLimiterAIMD(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name      string
+		latency   time.Duration
+		err       error
+		saturate  bool
+		wantLimit int
+	}{
+		{"healthy and saturated grows by one", time.Millisecond, nil, true, 9},
+		{"healthy but idle stays", time.Millisecond, nil, false, 8},
+		{"slow shrinks", 100 * time.Millisecond, nil, true, 6},
+		{"failing shrinks", time.Millisecond, errFailed, true, 6},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewAdaptiveLimiter(AdaptiveConfig{
+				InitialLimit:  8,
+				MaxLimit:      20,
+				LatencyTarget: 10 * time.Millisecond,
+				MaxErrorRate:  0.5,
+				Window:        4,
+			})
+			fillWindow(t, l, tc.latency, tc.err, tc.saturate)
+			if got := l.Limit(); got != tc.wantLimit {
+				t.Fatalf("Limit = %d after one window, want %d", got, tc.wantLimit)
+			}
+		})
+	}
+}
+
+func TestAdaptiveLimiterBounds(t *testing.T) {
+	l := NewAdaptiveLimiter(AdaptiveConfig{InitialLimit: 2, MinLimit: 2, MaxLimit: 3, LatencyTarget: time.Millisecond, Window: 1})
+	for range 5 {
+		fillWindow(t, l, 0, nil, true)
+	}
+	if got := l.Limit(); got != 3 {
+		t.Fatalf("Limit = %d after healthy windows, want the maximum 3", got)
+	}
+	for range 5 {
+		fillWindow(t, l, time.Second, nil, true)
+	}
+	if got := l.Limit(); got != 2 {
+		t.Fatalf("Limit = %d after slow windows, want the minimum 2", got)
+	}
+
+	stats := l.Stats()
+	if stats.Increases != 1 || stats.Decreases != 5 || stats.LastLatency != time.Second {
+		t.Fatalf("Stats = %+v, want 1 increase, 5 decreases and a last latency of 1s", stats)
+	}
+}
+
+func TestAdaptiveLimiterAcquireBlocks(t *testing.T) {
+	l := NewAdaptiveLimiter(AdaptiveConfig{InitialLimit: 1})
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire above the limit = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(context.Background())
+		acquired <- err
+	}()
+	release(nil)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("Acquire after a release: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Acquire was not woken up by a release")
+	}
+}
+
+func TestPoolWithConcurrencyLimiter(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	l := NewAdaptiveLimiter(AdaptiveConfig{InitialLimit: 1, MaxLimit: 2, Window: 2})
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		cur := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return n, nil
+	}, WithWorkers(6), WithQueueSize(50), WithConcurrencyLimiter(l))
+
+	for i := range 50 {
+		p.Submit(context.Background(), i)
+	}
+	p.Shutdown(context.Background())
+	if got := len(collect(t, p)); got != 50 {
+		t.Fatalf("got %d results, want 50", got)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("%d jobs ran at once with 6 workers, want at most the maximum limit 2", got)
+	}
+}
@@ -0,0 +1,112 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrJobTimeout is returned for a job that did not finish within the timeout
+// set by WithJobTimeout. It wraps context.DeadlineExceeded.
+var ErrJobTimeout = fmt.Errorf("workerpool: job timed out: %w", context.DeadlineExceeded)
+
+// PanicError is the error of a job that panicked.
+type PanicError struct {
+	Value any    // the value passed to panic
+	Stack []byte // stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("workerpool: job panicked: %v\n%s", e.Value, e.Stack)
+}
+
+// WithJobTimeout runs every attempt of a job under its own deadline. A job
+// that ignores its context and hangs is abandoned when the deadline passes,
+// so it cannot stall the worker. An abandoned job gives its
+// WithConcurrencyLimiter slot back straight away, reported as a timeout, so
+// hung jobs cannot starve the limiter. Its goroutine keeps running until the
+// job function returns and nothing bounds how many pile up, so job functions
+// should return when their context is done.
+func WithJobTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.jobTimeout = d
+	}
+}
+
+// safeCall runs the job function and turns a panic into a PanicError.
+func (p *Pool[In, Out]) safeCall(ctx context.Context, in In) (value Out, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return p.fn(ctx, in)
+}
+
+// isolatedCall runs the job function with the job timeout, if any. It calls
+// finished exactly once, with the job's error, when the function returns or
+// when the job is abandoned, whichever comes first.
+func (p *Pool[In, Out]) isolatedCall(ctx context.Context, in In, finished func(error)) (Out, error) {
+	if p.cfg.jobTimeout <= 0 {
+		value, err := p.safeCall(ctx, in)
+		finished(err)
+		return value, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.jobTimeout)
+	defer cancel()
+	var once sync.Once
+	finish := func(err error) { once.Do(func() { finished(err) }) }
+
+	type outcome struct {
+		value Out
+		err   error
+	}
+	// Buffered so an abandoned job can still finish without blocking
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := p.safeCall(ctx, in)
+		if err == nil && ctx.Err() == context.DeadlineExceeded {
+			finish(ErrJobTimeout) // returned just as it was abandoned
+		} else {
+			finish(err)
+		}
+		done <- outcome{value, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-ctx.Done():
+		var zero Out
+		if p.ctx.Err() != nil {
+			finish(p.ctx.Err())
+			return zero, p.ctx.Err()
+		}
+		finish(ErrJobTimeout)
+		p.summary.timeouts.Add(1)
+		return zero, ErrJobTimeout
+	}
+}
+
+// replace swaps a worker whose job panicked for a fresh one. A worker that
+// Resize already retired is not replaced.
+func (p *Pool[In, Out]) replace(w *workerHandle) {
+	p.workerMu.Lock()
+	defer p.workerMu.Unlock()
+	for i, a := range p.active {
+		if a == w {
+			p.active = append(p.active[:i], p.active[i+1:]...)
+			p.startWorker()
+			return
+		}
+	}
+}
+
+func isPanic(err error) bool {
+	var pe *PanicError
+	return errors.As(err, &pe)
+}
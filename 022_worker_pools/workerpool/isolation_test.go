@@ -0,0 +1,110 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPanicIsolation(t *testing.T) {
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		if n%2 == 0 {
+			panic("even job")
+		}
+		return n, nil
+	}, WithWorkers(2), WithQueueSize(10))
+	for i := range 6 {
+		p.Submit(context.Background(), i)
+	}
+	p.Shutdown(context.Background())
+
+	for _, r := range collect(t, p) {
+		var pe *PanicError
+		if panicked := errors.As(r.Err, &pe); panicked != (r.Job%2 == 0) {
+			t.Errorf("job %d: Err = %v", r.Job, r.Err)
+		} else if panicked && (pe.Value != "even job" || len(pe.Stack) == 0) {
+			t.Errorf("job %d: PanicError %v with a %d byte stack", r.Job, pe.Value, len(pe.Stack))
+		}
+	}
+	if s := p.Summary(); s.Panics != 3 || s.Succeeded != 3 {
+		t.Fatalf("Summary = %+v, want 3 panics and 3 successes", s)
+	}
+}
+
+func TestPanickedWorkerIsReplaced(t *testing.T) {
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		if n == 0 {
+			panic("boom")
+		}
+		return n, nil
+	}, WithWorkers(1))
+
+	p.Submit(context.Background(), 0)
+	if r := <-p.Results(); !isPanic(r.Err) {
+		t.Fatalf("Err = %v, want a PanicError", r.Err)
+	}
+	// The only worker panicked; its replacement runs the next job
+	p.Submit(context.Background(), 1)
+	if r := <-p.Results(); r.Err != nil || r.Worker == 1 {
+		t.Fatalf("result %+v, want a success from a new worker", r)
+	}
+	if got := p.Size(); got != 1 {
+		t.Fatalf("Size = %d after a panic, want 1", got)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		if n == 0 {
+			<-hang // ignores its context
+		}
+		return n, nil
+	}, WithJobTimeout(10*time.Millisecond), WithQueueSize(2))
+
+	p.Submit(context.Background(), 0)
+	p.Submit(context.Background(), 1)
+	p.Shutdown(context.Background())
+
+	results := collect(t, p)
+	if len(results) != 2 || !errors.Is(results[0].Err, ErrJobTimeout) || results[1].Err != nil {
+		t.Fatalf("results %+v, want job 0 to time out and job 1 to succeed", results)
+	}
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Fatal("ErrJobTimeout does not wrap context.DeadlineExceeded")
+	}
+	if got := p.Summary().Timeouts; got != 1 {
+		t.Fatalf("Summary.Timeouts = %d, want 1", got)
+	}
+}
+
+// TestAbandonedJobsReleaseTheLimiter checks that hung jobs do not keep their
+// limiter slot: with a limit of one, a single slot held forever would stall
+// every job after it.
+func TestAbandonedJobsReleaseTheLimiter(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	l := NewAdaptiveLimiter(AdaptiveConfig{MinLimit: 1, MaxLimit: 1})
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		if n < 3 {
+			<-hang
+		}
+		return n, nil
+	}, WithWorkers(2), WithQueueSize(10), WithJobTimeout(10*time.Millisecond), WithConcurrencyLimiter(l))
+
+	for i := range 6 {
+		p.Submit(context.Background(), i)
+	}
+	p.Shutdown(context.Background())
+
+	for _, r := range collect(t, p) {
+		if hung := r.Job < 3; hung != errors.Is(r.Err, ErrJobTimeout) {
+			t.Errorf("job %d: Err = %v", r.Job, r.Err)
+		}
+	}
+	if stats := l.Stats(); stats.InFlight != 0 {
+		t.Fatalf("%d limiter slots still held, want 0", stats.InFlight)
+	}
+}
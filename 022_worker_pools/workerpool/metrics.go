@@ -0,0 +1,33 @@
+package workerpool
+
+import "time"
+
+// Metrics receives instrumentation from a pool. The metrics package provides
+// an implementation with a Prometheus text exposition handler.
+type Metrics interface {
+	// QueueDepth reports the number of jobs waiting in the queue.
+	QueueDepth(n int)
+	// Workers reports the number of busy and idle workers.
+	Workers(busy, idle int)
+	// JobDone reports a finished job, including all of its attempts.
+	JobDone(latency time.Duration, err error)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) QueueDepth(int)               {}
+func (noopMetrics) Workers(int, int)             {}
+func (noopMetrics) JobDone(time.Duration, error) {}
+
+// WithMetrics reports the pool's activity to m.
+func WithMetrics(m Metrics) Option {
+	return func(c *config) {
+		c.metrics = m
+	}
+}
+
+// reportWorkers publishes the worker counts for a pool of the given size.
+func (p *Pool[In, Out]) reportWorkers(size int) {
+	busy := int(p.busy.Load())
+	p.cfg.metrics.Workers(busy, max(size-busy, 0))
+}
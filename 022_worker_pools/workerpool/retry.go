@@ -0,0 +1,184 @@
+package workerpool
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy describes how failed jobs are retried.
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts including the first one; 0 or 1 disables retries
+	InitialBackoff time.Duration // delay before the second attempt
+	MaxBackoff     time.Duration // upper bound for the delay; 0 means no bound
+	Multiplier     float64       // growth factor between attempts; defaults to 2
+	Jitter         float64       // fraction of the delay that is randomised, between 0 and 1
+	Retryable      func(error) bool
+}
+
+// Backoff returns the delay before the given attempt (attempt 2 is the first
+// retry).
+func (r RetryPolicy) Backoff(attempt int) time.Duration {
+	mult := r.Multiplier
+	if mult <= 0 {
+		mult = 2
+	}
+	d := float64(r.InitialBackoff)
+	for i := 2; i < attempt; i++ {
+		d *= mult
+		if r.MaxBackoff > 0 && d > float64(r.MaxBackoff) {
+			break
+		}
+	}
+	if r.MaxBackoff > 0 && d > float64(r.MaxBackoff) {
+		d = float64(r.MaxBackoff)
+	}
+	if j := min(max(r.Jitter, 0), 1); j > 0 {
+		d -= d * j * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+func (r RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if attempt >= r.MaxAttempts {
+		return false
+	}
+	return r.Retryable == nil || r.Retryable(err)
+}
+
+// WithRetry retries failed jobs according to policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = policy
+	}
+}
+
+// DeadLetterSink receives the final result of every job that failed all of
+// its attempts.
+type DeadLetterSink[In, Out any] interface {
+	Put(r Result[In, Out])
+}
+
+// WithDeadLetter sends jobs that run out of attempts to sink. The type
+// parameters must match the pool the option is passed to, otherwise New
+// returns ErrDeadLetterType.
+func WithDeadLetter[In, Out any](sink DeadLetterSink[In, Out]) Option {
+	return func(c *config) {
+		c.deadLetter = sink
+	}
+}
+
+// DeadLetterQueue is an in-memory DeadLetterSink that can be inspected and
+// replayed.
+type DeadLetterQueue[In, Out any] struct {
+	mu    sync.Mutex
+	items []Result[In, Out]
+}
+
+// Put implements DeadLetterSink.
+func (q *DeadLetterQueue[In, Out]) Put(r Result[In, Out]) {
+	q.mu.Lock()
+	q.items = append(q.items, r)
+	q.mu.Unlock()
+}
+
+// Items returns a copy of the dead-lettered results.
+func (q *DeadLetterQueue[In, Out]) Items() []Result[In, Out] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]Result[In, Out](nil), q.items...)
+}
+
+// Len returns the number of dead-lettered results.
+func (q *DeadLetterQueue[In, Out]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Replay removes the dead-lettered jobs from the queue and submits them to
+// pool. Jobs that cannot be submitted are put back.
+func (q *DeadLetterQueue[In, Out]) Replay(ctx context.Context, pool *Pool[In, Out]) error {
+	q.mu.Lock()
+	items := q.items
+	q.items = nil
+	q.mu.Unlock()
+
+	for i, r := range items {
+		if err := pool.Submit(ctx, r.Job); err != nil {
+			q.mu.Lock()
+			q.items = append(items[i:], q.items...)
+			q.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// Summary counts the outcome of the jobs processed by a pool.
+type Summary struct {
+	Succeeded    int // jobs that eventually succeeded
+	Failed       int // jobs that failed all of their attempts
+	Retries      int // extra attempts made across all jobs
+	DeadLettered int // failed jobs handed to the dead-letter sink
+	Panics       int // jobs whose last attempt panicked
+	Timeouts     int // attempts abandoned by the job timeout
+}
+
+type summaryCounters struct {
+	succeeded, failed, retries, deadLettered, panics, timeouts atomic.Int64
+}
+
+// Summary returns the outcome counts so far. After Results has been closed it
+// is the final summary.
+func (p *Pool[In, Out]) Summary() Summary {
+	return Summary{
+		Succeeded:    int(p.summary.succeeded.Load()),
+		Failed:       int(p.summary.failed.Load()),
+		Retries:      int(p.summary.retries.Load()),
+		DeadLettered: int(p.summary.deadLettered.Load()),
+		Panics:       int(p.summary.panics.Load()),
+		Timeouts:     int(p.summary.timeouts.Load()),
+	}
+}
+
+type attemptKey struct{}
+
+// Attempt returns the attempt number of the job running with ctx, starting at
+// 1. It returns 0 outside of a pool job.
+func Attempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+// run calls the job function, retrying it according to the retry policy.
+func (p *Pool[In, Out]) run(in In) (Out, int, error) {
+	for attempt := 1; ; attempt++ {
+		value, err := p.call(in, attempt)
+		if err == nil || !p.cfg.retry.shouldRetry(attempt, err) {
+			return value, attempt, err
+		}
+
+		p.summary.retries.Add(1)
+		select {
+		case <-time.After(p.cfg.retry.Backoff(attempt + 1)):
+		case <-p.ctx.Done():
+			return value, attempt, err
+		}
+	}
+}
+
+// record updates the summary and dead-letters a failed result.
+func (p *Pool[In, Out]) record(r Result[In, Out]) {
+	if r.Err == nil {
+		p.summary.succeeded.Add(1)
+		return
+	}
+	p.summary.failed.Add(1)
+	if p.deadLetter != nil {
+		p.deadLetter.Put(r)
+		p.summary.deadLettered.Add(1)
+	}
+}
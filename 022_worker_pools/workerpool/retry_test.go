@@ -0,0 +1,151 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{2, 100 * time.Millisecond},
+		{3, 200 * time.Millisecond},
+		{4, 400 * time.Millisecond},
+		{5, 800 * time.Millisecond},
+		{6, time.Second}, // capped
+		{50, time.Second},
+	}
+	for _, tc := range tests {
+		if got := policy.Backoff(tc.attempt); got != tc.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+
+	policy.Multiplier = 3
+	if got := policy.Backoff(3); got != 300*time.Millisecond {
+		t.Errorf("Backoff(3) with multiplier 3 = %v, want 300ms", got)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, Jitter: 0.5}
+	for range 100 {
+		if got := policy.Backoff(2); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("Backoff with 50%% jitter = %v, want between 50ms and 100ms", got)
+		}
+	}
+}
+
+var errPermanent = errors.New("permanent")
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int   // attempts that fail before one succeeds
+		err          error // error of the failing attempts
+		wantAttempts int
+		wantErr      error
+	}{
+		{"succeeds first time", 0, nil, 1, nil},
+		{"succeeds on a retry", 2, errors.New("transient"), 3, nil},
+		{"runs out of attempts", 10, errors.New("transient"), 4, errors.New("transient")},
+		{"not retryable", 10, errPermanent, 1, errPermanent},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var dlq DeadLetterQueue[int, int]
+			p := newPool(t, func(ctx context.Context, n int) (int, error) {
+				if Attempt(ctx) <= tc.failures {
+					return 0, tc.err
+				}
+				return n, nil
+			},
+				WithRetry(RetryPolicy{
+					MaxAttempts:    4,
+					InitialBackoff: time.Millisecond,
+					Retryable:      func(err error) bool { return err != errPermanent },
+				}),
+				WithDeadLetter[int, int](&dlq),
+			)
+			p.Submit(context.Background(), 7)
+			p.Shutdown(context.Background())
+			results := collect(t, p)
+
+			r := results[0]
+			if r.Attempts != tc.wantAttempts {
+				t.Errorf("Attempts = %d, want %d", r.Attempts, tc.wantAttempts)
+			}
+			if (r.Err == nil) != (tc.wantErr == nil) {
+				t.Fatalf("Err = %v, want %v", r.Err, tc.wantErr)
+			}
+
+			summary := p.Summary()
+			if summary.Retries != tc.wantAttempts-1 {
+				t.Errorf("Summary.Retries = %d, want %d", summary.Retries, tc.wantAttempts-1)
+			}
+			wantDead := 0
+			if tc.wantErr != nil {
+				wantDead = 1
+			}
+			if dlq.Len() != wantDead || summary.DeadLettered != wantDead || summary.Failed != wantDead {
+				t.Errorf("dead-lettered %d (summary %+v), want %d", dlq.Len(), summary, wantDead)
+			}
+		})
+	}
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var dlq DeadLetterQueue[int, int]
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		if down.Load() {
+			return 0, errors.New("down")
+		}
+		return n, nil
+	}, WithDeadLetter[int, int](&dlq), WithQueueSize(3))
+
+	for i := range 3 {
+		p.Submit(context.Background(), i)
+		<-p.Results()
+	}
+	if dlq.Len() != 3 {
+		t.Fatalf("dead-lettered %d jobs, want 3", dlq.Len())
+	}
+
+	down.Store(false)
+	if err := dlq.Replay(context.Background(), p); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	p.Shutdown(context.Background())
+	for _, r := range collect(t, p) {
+		if r.Err != nil {
+			t.Errorf("replayed job %d failed: %v", r.Job, r.Err)
+		}
+	}
+	if dlq.Len() != 0 {
+		t.Fatalf("%d jobs left after Replay, want 0", dlq.Len())
+	}
+}
+
+func TestDeadLetterReplayKeepsUnsubmitted(t *testing.T) {
+	var dlq DeadLetterQueue[int, int]
+	for i := range 3 {
+		dlq.Put(Result[int, int]{Job: i})
+	}
+	p := newPool(t, double)
+	p.Shutdown(context.Background())
+
+	if err := dlq.Replay(context.Background(), p); err != ErrClosed {
+		t.Fatalf("Replay to a closed pool = %v, want %v", err, ErrClosed)
+	}
+	if dlq.Len() != 3 {
+		t.Fatalf("%d jobs kept, want 3", dlq.Len())
+	}
+}
@@ -0,0 +1,190 @@
+package workerpool
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of the pool used by scaling policies.
+type Stats struct {
+	Workers    int             // number of running workers
+	Busy       int             // workers currently processing a job
+	QueueDepth int             // jobs waiting in the queue
+	Latencies  []time.Duration // recent job durations, sorted ascending
+}
+
+// Percentile returns the job latency at q (0 < q <= 1), or 0 when no job has
+// finished yet.
+func (s Stats) Percentile(q float64) time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	i := int(q*float64(len(s.Latencies))+0.5) - 1
+	i = max(0, min(i, len(s.Latencies)-1))
+	return s.Latencies[i]
+}
+
+// Policy decides how many workers the pool should run. The result is clamped
+// to the bounds set by WithScaling.
+type Policy interface {
+	Desired(s Stats) int
+}
+
+// PolicyFunc adapts an ordinary function to the Policy interface.
+type PolicyFunc func(s Stats) int
+
+// Desired calls f(s).
+func (f PolicyFunc) Desired(s Stats) int {
+	return f(s)
+}
+
+// QueueDepthPolicy runs one worker for every JobsPerWorker jobs that are
+// queued or being processed.
+type QueueDepthPolicy struct {
+	JobsPerWorker int
+}
+
+// Desired implements Policy.
+func (q QueueDepthPolicy) Desired(s Stats) int {
+	per := max(q.JobsPerWorker, 1)
+	return (s.QueueDepth + s.Busy + per - 1) / per
+}
+
+// LatencyPolicy adds a worker while the latency at Percentile is above Target
+// and jobs are waiting, and removes one once it drops below half of Target.
+type LatencyPolicy struct {
+	Percentile float64
+	Target     time.Duration
+}
+
+// Desired implements Policy.
+func (l LatencyPolicy) Desired(s Stats) int {
+	observed := s.Percentile(l.Percentile)
+	switch {
+	case observed > l.Target && s.QueueDepth > 0:
+		return s.Workers + 1
+	case observed < l.Target/2:
+		return s.Workers - 1
+	}
+	return s.Workers
+}
+
+// WithScaling bounds the number of workers for Resize and autoscaling.
+// The default bounds are the initial number of workers.
+func WithScaling(minWorkers, maxWorkers int) Option {
+	return func(c *config) {
+		c.minWorkers = max(minWorkers, 1)
+		c.maxWorkers = max(maxWorkers, c.minWorkers)
+	}
+}
+
+// WithAutoscale evaluates policy every interval and resizes the pool to the
+// number of workers it asks for. An interval that is not positive defaults
+// to one second.
+func WithAutoscale(policy Policy, interval time.Duration) Option {
+	return func(c *config) {
+		c.policy = policy
+		c.interval = interval
+		if c.interval <= 0 {
+			c.interval = time.Second
+		}
+	}
+}
+
+func (c *config) clamp(n int) int {
+	return max(c.minWorkers, min(n, c.maxWorkers))
+}
+
+type workerHandle struct {
+	id   int
+	stop chan struct{}
+}
+
+// startWorker must be called with workerMu held.
+func (p *Pool[In, Out]) startWorker() {
+	p.nextID++
+	w := &workerHandle{id: p.nextID, stop: make(chan struct{})}
+	p.active = append(p.active, w)
+	p.live++
+	go p.worker(w)
+}
+
+// Resize changes the number of workers to n, clamped to the scaling bounds,
+// and returns the new size. Retired workers finish their current job and
+// exit; the jobs channel stays open for the others. Resize keeps working while
+// Shutdown drains the queue.
+func (p *Pool[In, Out]) Resize(n int) int {
+	p.workerMu.Lock()
+	defer p.workerMu.Unlock()
+
+	if p.finished || p.ctx.Err() != nil {
+		return len(p.active)
+	}
+
+	n = p.cfg.clamp(n)
+	for len(p.active) < n {
+		p.startWorker()
+	}
+	for len(p.active) > n {
+		last := len(p.active) - 1
+		close(p.active[last].stop)
+		p.active = p.active[:last]
+	}
+	p.reportWorkers(n)
+	return n
+}
+
+// Size returns the number of running workers.
+func (p *Pool[In, Out]) Size() int {
+	p.workerMu.Lock()
+	defer p.workerMu.Unlock()
+	return len(p.active)
+}
+
+// Stats returns a snapshot of the pool.
+func (p *Pool[In, Out]) Stats() Stats {
+	return Stats{
+		Workers:    p.Size(),
+		Busy:       int(p.busy.Load()),
+		QueueDepth: len(p.jobs),
+		Latencies:  p.latency.snapshot(),
+	}
+}
+
+func (p *Pool[In, Out]) autoscale() {
+	ticker := time.NewTicker(p.cfg.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.Resize(p.cfg.policy.Desired(p.Stats()))
+		case <-p.done:
+			return
+		}
+	}
+}
+
+const latencyWindowSize = 128
+
+// latencyWindow keeps the durations of the most recent jobs.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [latencyWindowSize]time.Duration
+	n       int
+}
+
+func (l *latencyWindow) record(d time.Duration) {
+	l.mu.Lock()
+	l.samples[l.n%latencyWindowSize] = d
+	l.n++
+	l.mu.Unlock()
+}
+
+func (l *latencyWindow) snapshot() []time.Duration {
+	l.mu.Lock()
+	out := slices.Clone(l.samples[:min(l.n, latencyWindowSize)])
+	l.mu.Unlock()
+	slices.Sort(out)
+	return out
+}
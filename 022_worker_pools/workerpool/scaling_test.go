@@ -0,0 +1,132 @@
+package workerpool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResize(t *testing.T) {
+	p := newPool(t, double, WithWorkers(2), WithScaling(1, 8))
+	tests := []struct {
+		n, want int
+	}{
+		{6, 6},
+		{20, 8}, // clamped to the maximum
+		{3, 3},
+		{0, 1}, // clamped to the minimum
+	}
+	for _, tc := range tests {
+		if got := p.Resize(tc.n); got != tc.want {
+			t.Errorf("Resize(%d) = %d, want %d", tc.n, got, tc.want)
+		}
+		if got := p.Size(); got != tc.want {
+			t.Errorf("Size after Resize(%d) = %d, want %d", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestResizeAfterShutdown(t *testing.T) {
+	p := newPool(t, double, WithWorkers(2), WithScaling(1, 8))
+	p.Shutdown(context.Background())
+	collect(t, p)
+	if got := p.Resize(6); got != 2 {
+		t.Fatalf("Resize after Shutdown = %d, want it to leave the size at 2", got)
+	}
+}
+
+func TestQueueDepthPolicy(t *testing.T) {
+	tests := []struct {
+		per, queued, busy, want int
+	}{
+		{5, 0, 0, 0},
+		{5, 1, 0, 1},
+		{5, 9, 1, 2},
+		{5, 10, 1, 3},
+		{0, 3, 0, 3}, // JobsPerWorker defaults to 1
+	}
+	for _, tc := range tests {
+		got := QueueDepthPolicy{JobsPerWorker: tc.per}.Desired(Stats{QueueDepth: tc.queued, Busy: tc.busy})
+		if got != tc.want {
+			t.Errorf("%d per worker with %d queued and %d busy: Desired = %d, want %d",
+				tc.per, tc.queued, tc.busy, got, tc.want)
+		}
+	}
+}
+
+func TestLatencyPolicy(t *testing.T) {
+	policy := LatencyPolicy{Percentile: 0.9, Target: 100 * time.Millisecond}
+	ms := func(ds ...int) []time.Duration {
+		out := make([]time.Duration, len(ds))
+		for i, d := range ds {
+			out[i] = time.Duration(d) * time.Millisecond
+		}
+		return out
+	}
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		queued    int
+		want      int
+	}{
+		{"slow with a backlog grows", ms(10, 20, 150, 200), 3, 5},
+		{"slow without a backlog holds", ms(10, 20, 150, 200), 0, 4},
+		{"between half and target holds", ms(60, 70, 80, 90), 3, 4},
+		{"fast shrinks", ms(10, 20, 30, 40), 3, 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := policy.Desired(Stats{Workers: 4, QueueDepth: tc.queued, Latencies: tc.latencies})
+			if got != tc.want {
+				t.Fatalf("Desired = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	s := Stats{Latencies: []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}}
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.5, 5}, {0.9, 9}, {0.99, 10}, {1, 10}, {0.01, 1},
+	}
+	for _, tc := range tests {
+		if got := s.Percentile(tc.q); got != tc.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tc.q, got, tc.want)
+		}
+	}
+	if got := (Stats{}).Percentile(0.9); got != 0 {
+		t.Errorf("Percentile without samples = %v, want 0", got)
+	}
+}
+
+func TestAutoscale(t *testing.T) {
+	desired := make(chan int, 1)
+	desired <- 5
+	p := newPool(t, double, WithWorkers(1), WithScaling(1, 4), WithAutoscale(PolicyFunc(func(s Stats) int {
+		select {
+		case n := <-desired:
+			return n
+		default:
+			return s.Workers
+		}
+	}), time.Millisecond))
+
+	deadline := time.Now().Add(time.Second)
+	for p.Size() != 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Size = %d, want the policy's 5 clamped to 4", p.Size())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAutoscaleZeroInterval(t *testing.T) {
+	// A non-positive interval falls back to the default instead of panicking
+	p := newPool(t, double, WithAutoscale(QueueDepthPolicy{JobsPerWorker: 1}, 0))
+	if p.cfg.interval != time.Second {
+		t.Fatalf("interval = %v, want 1s", p.cfg.interval)
+	}
+}
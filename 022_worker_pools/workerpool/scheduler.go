@@ -0,0 +1,240 @@
+package workerpool
+
+import (
+	"container/heap"
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// SchedulingMode selects how a Scheduler orders queued jobs.
+type SchedulingMode int
+
+const (
+	// StrictPriority always dispatches the job with the highest priority
+	// first, in submission order within the same priority.
+	StrictPriority SchedulingMode = iota
+	// WeightedFair shares the pool between tenants in proportion to their
+	// weights, so a tenant with a large backlog cannot block the others.
+	WeightedFair
+)
+
+// SchedulerConfig configures a Scheduler.
+type SchedulerConfig struct {
+	Mode     SchedulingMode
+	Weights  map[string]int // tenant weights for WeightedFair; missing tenants weigh 1
+	MaxWait  time.Duration  // starvation guard: jobs queued longer are dispatched first; 0 disables it
+	Capacity int            // maximum number of queued jobs; Submit blocks when full. Defaults to 1024
+}
+
+// SchedulerStats describes the jobs that went through a Scheduler.
+type SchedulerStats struct {
+	Queued     int
+	Dispatched int
+	Promoted   int // jobs dispatched early by the starvation guard
+	Dropped    int // jobs the pool refused
+}
+
+type scheduled[In any] struct {
+	in       In
+	priority int
+	tenant   string
+	seq      int
+	finish   float64 // virtual finish time for WeightedFair
+	queuedAt time.Time
+	index    int           // position in the heap
+	arrival  *list.Element // position in the arrival list
+}
+
+// Scheduler sits in front of a Pool and decides which queued job the workers
+// get next, instead of the single FIFO jobs channel. Jobs carry a priority and
+// a tenant. Give the pool a small queue (WithQueueSize(0) or 1) so that jobs
+// wait in the scheduler, where they can be reordered.
+type Scheduler[In, Out any] struct {
+	pool *Pool[In, Out]
+	cfg  SchedulerConfig
+
+	mu         sync.Mutex
+	queue      schedHeap[In]
+	arrivals   *list.List // queued jobs, oldest first
+	seq        int
+	vtime      float64
+	lastFinish map[string]float64
+	stats      SchedulerStats
+	closed     bool
+
+	slots chan struct{}
+	ready chan struct{}
+	done  chan struct{}
+}
+
+// NewScheduler starts a scheduler that feeds pool.
+func NewScheduler[In, Out any](pool *Pool[In, Out], cfg SchedulerConfig) *Scheduler[In, Out] {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = 1024
+	}
+	s := &Scheduler[In, Out]{
+		pool:       pool,
+		cfg:        cfg,
+		queue:      schedHeap[In]{mode: cfg.Mode},
+		arrivals:   list.New(),
+		lastFinish: make(map[string]float64),
+		slots:      make(chan struct{}, cfg.Capacity),
+		ready:      make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	go s.dispatch()
+	return s
+}
+
+// Submit queues a job with a priority (higher runs first) and a tenant. It
+// blocks while the scheduler is full.
+func (s *Scheduler[In, Out]) Submit(ctx context.Context, in In, priority int, tenant string) error {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		<-s.slots
+		return ErrClosed
+	}
+	it := &scheduled[In]{in: in, priority: priority, tenant: tenant, seq: s.seq, queuedAt: time.Now()}
+	s.seq++
+	if s.cfg.Mode == WeightedFair {
+		weight := max(s.cfg.Weights[tenant], 1)
+		it.finish = max(s.vtime, s.lastFinish[tenant]) + 1/float64(weight)
+		s.lastFinish[tenant] = it.finish
+	}
+	it.arrival = s.arrivals.PushBack(it)
+	heap.Push(&s.queue, it)
+	s.stats.Queued++
+	s.mu.Unlock()
+
+	s.notify()
+	return nil
+}
+
+func (s *Scheduler[In, Out]) notify() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next removes the job to dispatch. It must be called with mu held and a
+// non-empty queue.
+func (s *Scheduler[In, Out]) next() *scheduled[In] {
+	var it *scheduled[In]
+	if oldest := s.arrivals.Front(); s.cfg.MaxWait > 0 && oldest != nil {
+		if o := oldest.Value.(*scheduled[In]); time.Since(o.queuedAt) >= s.cfg.MaxWait && o.index != 0 {
+			it = o
+			heap.Remove(&s.queue, o.index)
+			s.stats.Promoted++
+		}
+	}
+	if it == nil {
+		it = heap.Pop(&s.queue).(*scheduled[In])
+	}
+	s.arrivals.Remove(it.arrival)
+	s.vtime = max(s.vtime, it.finish)
+	s.stats.Queued--
+	return it
+}
+
+func (s *Scheduler[In, Out]) dispatch() {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		if s.queue.Len() == 0 {
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return
+			}
+			<-s.ready
+			continue
+		}
+		it := s.next()
+		s.mu.Unlock()
+		<-s.slots
+
+		// Blocks until a worker is free when the pool has no queue
+		err := s.pool.Submit(context.Background(), it.in)
+
+		s.mu.Lock()
+		if err != nil {
+			s.stats.Dropped++
+		} else {
+			s.stats.Dispatched++
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Stats returns the scheduler counters.
+func (s *Scheduler[In, Out]) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Close stops accepting jobs, waits for the queued ones to be handed to the
+// pool and then shuts the pool down.
+func (s *Scheduler[In, Out]) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.notify()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		s.pool.Cancel()
+		<-s.done
+	}
+	return s.pool.Shutdown(ctx)
+}
+
+// schedHeap orders jobs by priority or by virtual finish time.
+type schedHeap[In any] struct {
+	mode  SchedulingMode
+	items []*scheduled[In]
+}
+
+func (h schedHeap[In]) Len() int { return len(h.items) }
+
+func (h schedHeap[In]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.mode == WeightedFair && a.finish != b.finish {
+		return a.finish < b.finish
+	}
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (h schedHeap[In]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *schedHeap[In]) Push(x any) {
+	it := x.(*scheduled[In])
+	it.index = len(h.items)
+	h.items = append(h.items, it)
+}
+
+func (h *schedHeap[In]) Pop() any {
+	old := h.items
+	it := old[len(old)-1]
+	old[len(old)-1] = nil
+	h.items = old[:len(old)-1]
+	return it
+}
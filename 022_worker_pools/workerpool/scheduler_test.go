@@ -0,0 +1,149 @@
+package workerpool
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+type schedJob struct {
+	name     string
+	priority int
+	tenant   string
+}
+
+// runScheduled queues jobs while the pool's only worker is busy, then lets it
+// go and returns the names of the jobs in the order they ran.
+func runScheduled(t *testing.T, cfg SchedulerConfig, wait time.Duration, jobs []schedJob) ([]string, SchedulerStats) {
+	t.Helper()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var order []string
+	p := newPool(t, func(_ context.Context, name string) (string, error) {
+		switch name {
+		case "gate":
+			close(started)
+			<-release
+		case "blocker":
+		default:
+			order = append(order, name)
+		}
+		return name, nil
+	}, WithQueueSize(0))
+	s := NewScheduler(p, cfg)
+
+	// The worker holds the gate and the dispatcher is stuck handing over the
+	// blocker, so every job below waits in the scheduler
+	s.Submit(context.Background(), "gate", 0, "")
+	<-started
+	s.Submit(context.Background(), "blocker", 0, "")
+	for s.Stats().Queued > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for _, j := range jobs {
+		if err := s.Submit(context.Background(), j.name, j.priority, j.tenant); err != nil {
+			t.Fatalf("Submit(%s): %v", j.name, err)
+		}
+	}
+	time.Sleep(wait)
+	close(release)
+
+	// The pool has no room for results, so they are read while closing
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for range p.Results() {
+		}
+	}()
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	<-collected
+	return order, s.Stats()
+}
+
+func TestSchedulerOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SchedulerConfig
+		jobs []schedJob
+		want []string
+	}{
+		{
+			"strict priority",
+			SchedulerConfig{Mode: StrictPriority},
+			[]schedJob{{"low", 1, ""}, {"high1", 3, ""}, {"mid", 2, ""}, {"high2", 3, ""}},
+			[]string{"high1", "high2", "mid", "low"},
+		},
+		{
+			"weighted fair with equal weights",
+			SchedulerConfig{Mode: WeightedFair},
+			[]schedJob{{"a1", 0, "a"}, {"a2", 0, "a"}, {"a3", 0, "a"}, {"a4", 0, "a"}, {"b1", 0, "b"}, {"b2", 0, "b"}},
+			[]string{"a1", "b1", "a2", "b2", "a3", "a4"},
+		},
+		{
+			"weighted fair with weights",
+			SchedulerConfig{Mode: WeightedFair, Weights: map[string]int{"a": 3}},
+			[]schedJob{{"b1", 0, "b"}, {"b2", 0, "b"}, {"a1", 0, "a"}, {"a2", 0, "a"}, {"a3", 0, "a"}, {"a4", 0, "a"}},
+			[]string{"a1", "a2", "b1", "a3", "a4", "b2"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, stats := runScheduled(t, tc.cfg, 0, tc.jobs)
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("ran %v, want %v", got, tc.want)
+			}
+			if want := len(tc.jobs) + 2; stats.Dispatched != want || stats.Queued != 0 {
+				t.Fatalf("Stats = %+v, want %d dispatched and none queued", stats, want)
+			}
+		})
+	}
+}
+
+func TestSchedulerStarvationGuard(t *testing.T) {
+	cfg := SchedulerConfig{Mode: StrictPriority, MaxWait: time.Millisecond}
+	jobs := []schedJob{{"low", 0, ""}, {"high1", 5, ""}, {"high2", 5, ""}}
+	got, stats := runScheduled(t, cfg, 10*time.Millisecond, jobs)
+	if want := []string{"low", "high1", "high2"}; !slices.Equal(got, want) {
+		t.Fatalf("ran %v, want %v", got, want)
+	}
+	if stats.Promoted != 1 {
+		t.Fatalf("Promoted = %d, want 1", stats.Promoted)
+	}
+}
+
+func TestSchedulerSubmitAfterClose(t *testing.T) {
+	p := newPool(t, double)
+	s := NewScheduler(p, SchedulerConfig{})
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := s.Submit(context.Background(), 1, 0, ""); err != ErrClosed {
+		t.Fatalf("Submit after Close = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestSchedulerSubmitBlocksWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		<-release
+		return n, nil
+	}, WithQueueSize(0))
+	s := NewScheduler(p, SchedulerConfig{Capacity: 1})
+
+	// One job runs, one is held by the dispatcher and one fills the capacity
+	for i := range 3 {
+		if err := s.Submit(context.Background(), i, 0, ""); err != nil {
+			t.Fatalf("Submit(%d): %v", i, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Submit(ctx, 3, 0, ""); err != context.DeadlineExceeded {
+		t.Fatalf("Submit to a full scheduler = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
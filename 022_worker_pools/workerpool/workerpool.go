@@ -0,0 +1,360 @@
+// Package workerpool provides a reusable, generic version of the worker pools
+// shown in 022_worker_pools.
+//
+// A Pool has the same fan-out/fan-in shape as the examples: jobs are sent to a
+// shared channel, a fixed number of workers process them, and the results are
+// collected from a single results channel. On top of that it adds typed jobs,
+// context cancellation, a graceful Shutdown and optional ordering of results.
+// The number of workers can be changed at runtime with Resize or by a Policy,
+// and failed jobs can be retried with backoff and sent to a dead-letter sink.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed is returned by Submit after Shutdown has been called.
+var ErrClosed = errors.New("workerpool: pool is closed")
+
+// ErrDeadLetterType is returned by New when the sink passed to WithDeadLetter
+// was built for other job or result types than the pool.
+var ErrDeadLetterType = errors.New("workerpool: dead-letter sink does not match the pool's job and result types")
+
+// Func processes a single job. The context is cancelled when the pool is
+// cancelled, so long-running jobs should watch it.
+type Func[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// Ordering controls the order in which results are delivered.
+type Ordering int
+
+const (
+	// BestEffort delivers results as soon as a worker finishes them.
+	BestEffort Ordering = iota
+	// Preserve delivers results in the order the jobs were submitted.
+	Preserve
+)
+
+// Result is the outcome of one job.
+type Result[In, Out any] struct {
+	Seq      int // position of the job in submission order, starting at 0
+	Worker   int // id of the worker that processed the job
+	Attempts int // number of times the job was run
+	Job      In
+	Value    Out
+	Err      error
+}
+
+type config struct {
+	workers    int
+	minWorkers int
+	maxWorkers int
+	queueSize  int
+	ordering   Ordering
+	policy     Policy
+	interval   time.Duration
+	retry      RetryPolicy
+	deadLetter any
+	limiter    *AdaptiveLimiter
+	metrics    Metrics
+	jobTimeout time.Duration
+}
+
+// Option configures a Pool.
+type Option func(*config)
+
+// WithWorkers sets the number of workers. The default is 1.
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
+}
+
+// WithQueueSize sets the buffer size of the jobs and results channels.
+// The default is the number of workers.
+func WithQueueSize(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.queueSize = n
+		}
+	}
+}
+
+// WithOrdering sets the result ordering. The default is BestEffort.
+func WithOrdering(o Ordering) Option {
+	return func(c *config) {
+		c.ordering = o
+	}
+}
+
+type job[In any] struct {
+	seq int
+	in  In
+}
+
+// Pool is a generic worker pool. Create one with New, feed it with Submit,
+// read Results until the channel is closed and call Shutdown when done.
+type Pool[In, Out any] struct {
+	fn         Func[In, Out]
+	cfg        config
+	deadLetter DeadLetterSink[In, Out]
+	summary    summaryCounters
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	jobs    chan job[In]
+	out     chan Result[In, Out]
+	results chan Result[In, Out]
+
+	mu        sync.Mutex
+	seq       int
+	quit      chan struct{}
+	closeOnce sync.Once
+
+	workerMu sync.Mutex
+	active   []*workerHandle
+	live     int
+	nextID   int
+	finished bool
+	busy     atomic.Int64
+	latency  latencyWindow
+	done     chan struct{}
+}
+
+// New starts a pool that runs fn on every submitted job. Cancelling ctx stops
+// the workers; jobs that have not been picked up yet are dropped. It fails
+// with ErrDeadLetterType if the options do not fit the pool's types.
+func New[In, Out any](ctx context.Context, fn Func[In, Out], opts ...Option) (*Pool[In, Out], error) {
+	cfg := config{workers: 1, queueSize: -1, metrics: noopMetrics{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.minWorkers == 0 {
+		cfg.minWorkers, cfg.maxWorkers = cfg.workers, cfg.workers
+	}
+	cfg.workers = cfg.clamp(cfg.workers)
+	if cfg.queueSize < 0 {
+		cfg.queueSize = cfg.workers
+	}
+
+	var deadLetter DeadLetterSink[In, Out]
+	if cfg.deadLetter != nil {
+		sink, ok := cfg.deadLetter.(DeadLetterSink[In, Out])
+		if !ok {
+			return nil, ErrDeadLetterType
+		}
+		deadLetter = sink
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Pool[In, Out]{
+		fn:         fn,
+		cfg:        cfg,
+		deadLetter: deadLetter,
+		ctx:        ctx,
+		cancel:     cancel,
+		jobs:       make(chan job[In], cfg.queueSize),
+		results:    make(chan Result[In, Out], cfg.queueSize),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	p.out = p.results
+	if cfg.ordering == Preserve {
+		p.out = make(chan Result[In, Out], cfg.queueSize)
+		go p.reorder()
+	}
+
+	// Start workers
+	p.workerMu.Lock()
+	for w := 1; w <= cfg.workers; w++ {
+		p.startWorker()
+	}
+	p.reportWorkers(cfg.workers)
+	p.workerMu.Unlock()
+
+	if cfg.policy != nil {
+		go p.autoscale()
+	}
+
+	return p, nil
+}
+
+// Submit queues a job. It blocks while the queue is full and returns an error
+// if ctx is cancelled, the pool is cancelled, or the pool has been shut down.
+func (p *Pool[In, Out]) Submit(ctx context.Context, in In) error {
+	// Submitters are serialised so that sequence numbers follow queue order
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A ready queue would win the select below half of the time, so check
+	// for shutdown and cancellation first
+	select {
+	case <-p.quit:
+		return ErrClosed
+	default:
+	}
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case p.jobs <- job[In]{seq: p.seq, in: in}:
+		p.seq++
+		p.cfg.metrics.QueueDepth(len(p.jobs))
+		return nil
+	case <-p.quit:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Results returns the channel that receives the outcome of every job. It is
+// closed after Shutdown once all workers have returned. Callers must keep
+// draining it, otherwise the workers block.
+func (p *Pool[In, Out]) Results() <-chan Result[In, Out] {
+	return p.results
+}
+
+// Shutdown stops accepting new jobs and waits for the queued ones to finish.
+// If ctx expires first, the pool is cancelled and ctx.Err() is returned right
+// away. Workers may still be finishing their current job at that point, and
+// one that ignores its context keeps running; Results is closed once the
+// last of them returns.
+func (p *Pool[In, Out]) Shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.quit)
+		go func() {
+			// Wait for a blocked Submit to give up before closing the queue
+			p.mu.Lock()
+			close(p.jobs)
+			p.mu.Unlock()
+		}()
+	})
+
+	select {
+	case <-p.done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Cancel stops the pool immediately without waiting for queued jobs.
+func (p *Pool[In, Out]) Cancel() {
+	p.cancel()
+}
+
+func (p *Pool[In, Out]) worker(w *workerHandle) {
+	defer p.exit()
+	id := w.id
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-w.stop:
+			return
+		case j, ok := <-p.jobs:
+			if !ok {
+				return
+			}
+			p.busy.Add(1)
+			p.cfg.metrics.QueueDepth(len(p.jobs))
+			p.reportWorkers(p.Size())
+			start := time.Now()
+			value, attempts, err := p.run(j.in)
+			p.cfg.metrics.JobDone(time.Since(start), err)
+			p.busy.Add(-1)
+			p.reportWorkers(p.Size())
+			r := Result[In, Out]{Seq: j.seq, Worker: id, Attempts: attempts, Job: j.in, Value: value, Err: err}
+			p.record(r)
+			select {
+			case p.out <- r:
+			case <-p.ctx.Done():
+				return
+			}
+			if isPanic(err) {
+				p.summary.panics.Add(1)
+				// Hand over to a fresh worker; this one exits
+				p.replace(w)
+				return
+			}
+		}
+	}
+}
+
+// call runs a single attempt of a job.
+func (p *Pool[In, Out]) call(in In, attempt int) (Out, error) {
+	release := func(error) {}
+	if p.cfg.limiter != nil {
+		var err error
+		release, err = p.cfg.limiter.Acquire(p.ctx)
+		if err != nil {
+			var zero Out
+			return zero, err
+		}
+	}
+
+	// The limiter slot is released when the job function returns or when a
+	// timed-out job is abandoned, so hung jobs cannot hold on to it
+	start := time.Now()
+	value, err := p.isolatedCall(context.WithValue(p.ctx, attemptKey{}, attempt), in, release)
+	p.latency.record(time.Since(start))
+	return value, err
+}
+
+// exit closes the results once the last worker has returned.
+func (p *Pool[In, Out]) exit() {
+	p.workerMu.Lock()
+	defer p.workerMu.Unlock()
+	p.live--
+	if p.live == 0 {
+		p.finished = true
+		close(p.out)
+		close(p.done)
+	}
+}
+
+// reorder buffers out-of-order results and releases them by sequence number.
+func (p *Pool[In, Out]) reorder() {
+	defer close(p.results)
+	pending := make(map[int]Result[In, Out])
+	next := 0
+	flush := func() {
+		for {
+			r, ok := pending[next]
+			if !ok {
+				return
+			}
+			delete(pending, next)
+			next++
+			p.results <- r
+		}
+	}
+	for r := range p.out {
+		pending[r.Seq] = r
+		flush()
+	}
+	// Jobs dropped by cancellation leave gaps; deliver what is left in order.
+	for len(pending) > 0 {
+		if _, ok := pending[next]; !ok {
+			next++
+			continue
+		}
+		flush()
+	}
+}
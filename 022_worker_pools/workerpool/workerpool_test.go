@@ -0,0 +1,216 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func double(_ context.Context, n int) (int, error) {
+	return n * 2, nil
+}
+
+func newPool[In, Out any](t *testing.T, fn Func[In, Out], opts ...Option) *Pool[In, Out] {
+	t.Helper()
+	p, err := New(context.Background(), fn, opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(p.Cancel)
+	return p
+}
+
+// collect reads Results until it is closed, failing t after a second.
+func collect[In, Out any](t *testing.T, p *Pool[In, Out]) []Result[In, Out] {
+	t.Helper()
+	var got []Result[In, Out]
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case r, ok := <-p.Results():
+			if !ok {
+				return got
+			}
+			got = append(got, r)
+		case <-timeout:
+			t.Fatal("Results was not closed")
+		}
+	}
+}
+
+func TestSubmitAndShutdown(t *testing.T) {
+	p := newPool(t, double, WithWorkers(3), WithQueueSize(10))
+	for i := range 10 {
+		if err := p.Submit(context.Background(), i); err != nil {
+			t.Fatalf("Submit(%d): %v", i, err)
+		}
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	results := collect(t, p)
+	if len(results) != 10 {
+		t.Fatalf("got %d results, want 10", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil || r.Value != r.Job*2 || r.Attempts != 1 {
+			t.Errorf("result %+v, want value %d after 1 attempt", r, r.Job*2)
+		}
+	}
+}
+
+func TestSubmitErrors(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		prepare func(p *Pool[int, int])
+		ctx     context.Context
+		want    error
+	}{
+		{"after Shutdown", func(p *Pool[int, int]) { p.Shutdown(context.Background()) }, context.Background(), ErrClosed},
+		{"after Cancel", func(p *Pool[int, int]) { p.Cancel() }, context.Background(), context.Canceled},
+		{"cancelled ctx", func(*Pool[int, int]) {}, cancelled, context.Canceled},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The queue has room, so a racy Submit would often succeed
+			for range 100 {
+				p := newPool(t, double, WithQueueSize(10))
+				tc.prepare(p)
+				if err := p.Submit(tc.ctx, 1); !errors.Is(err, tc.want) {
+					t.Fatalf("Submit = %v, want %v", err, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmitBlocksWhileQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	p := newPool(t, func(ctx context.Context, n int) (int, error) {
+		<-release
+		return n, nil
+	}, WithQueueSize(0))
+
+	if err := p.Submit(context.Background(), 1); err != nil { // taken by the worker
+		t.Fatalf("Submit: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, 2); err != context.DeadlineExceeded {
+		t.Fatalf("Submit to a full queue = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestCancelStopsWorkers(t *testing.T) {
+	started := make(chan struct{})
+	p := newPool(t, func(ctx context.Context, n int) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	p.Submit(context.Background(), 1)
+	<-started
+	p.Cancel()
+	collect(t, p)
+}
+
+func TestShutdownReturnsWhenCtxExpires(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p := newPool(t, func(_ context.Context, n int) (int, error) {
+		close(started)
+		<-release // ignores its context
+		return n, nil
+	})
+	p.Submit(context.Background(), 1)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	returned := make(chan error, 1)
+	go func() { returned <- p.Shutdown(ctx) }()
+
+	select {
+	case err := <-returned:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Shutdown = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown waited for a job that ignores its context")
+	}
+
+	close(release)
+	collect(t, p) // closed once the job returns
+}
+
+func TestOrdering(t *testing.T) {
+	tests := []struct {
+		ordering Ordering
+		want     []int // Seq of the results in delivery order
+	}{
+		{BestEffort, []int{1, 0}},
+		{Preserve, []int{0, 1}},
+	}
+	for _, tc := range tests {
+		t.Run(map[Ordering]string{BestEffort: "best effort", Preserve: "preserve"}[tc.ordering], func(t *testing.T) {
+			// Job 0 only finishes once job 1 is done
+			release := make(chan struct{})
+			p := newPool(t, func(_ context.Context, n int) (int, error) {
+				if n == 0 {
+					<-release
+				} else {
+					defer close(release)
+				}
+				return n, nil
+			}, WithWorkers(2), WithOrdering(tc.ordering))
+
+			p.Submit(context.Background(), 0)
+			p.Submit(context.Background(), 1)
+			p.Shutdown(context.Background())
+
+			var got []int
+			for _, r := range collect(t, p) {
+				got = append(got, r.Seq)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("delivered %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestReorderGaps feeds reorder results with gaps, as left by jobs dropped on
+// cancellation, and checks the rest still come out in submission order.
+func TestReorderGaps(t *testing.T) {
+	p := &Pool[int, int]{
+		out:     make(chan Result[int, int], 10),
+		results: make(chan Result[int, int], 10),
+	}
+	for _, seq := range []int{3, 0, 5, 1, 8} {
+		p.out <- Result[int, int]{Seq: seq}
+	}
+	close(p.out)
+	p.reorder()
+
+	var got []int
+	for r := range p.results {
+		got = append(got, r.Seq)
+	}
+	if want := []int{0, 1, 3, 5, 8}; !slices.Equal(got, want) {
+		t.Fatalf("delivered %v, want %v", got, want)
+	}
+}
+
+func TestNewRejectsMismatchedDeadLetter(t *testing.T) {
+	var dlq DeadLetterQueue[string, int]
+	if _, err := New(context.Background(), double, WithDeadLetter[string, int](&dlq)); err != ErrDeadLetterType {
+		t.Fatalf("New = %v, want %v", err, ErrDeadLetterType)
+	}
+}
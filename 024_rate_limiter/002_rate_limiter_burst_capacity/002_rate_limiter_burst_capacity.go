@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"time"
+
+	"go_sample_examples/024_rate_limiter/ratelimit"
 )
 
 func main() {
@@ -10,6 +14,8 @@ func main() {
 	// Rate Limiting with a Burst Capacity
 	// Demonstrate how to allow a burst of requests before enforcing the rate limit
 
+	goroutinesBefore := runtime.NumGoroutine()
+
 	requests := make(chan int, 5)
 
 	for i := 1; i <= 5; i++ {
@@ -17,24 +23,26 @@ func main() {
 	}
 	close(requests)
 
-	// Create a buffered channel to allow a burst of up to 3 requests
-	burstLimiter := make(chan time.Time, 3)
-
-	// Initially fill the burstLimiter channel to allow immediate processing of up to 3 requests
-	for i := 0; i < 3; i++ {
-		burstLimiter <- time.Now()
-	}
+	// Create a limiter that allows a burst of up to 3 requests and adds a token every 200 milliseconds.
+	// It wraps the buffered channel and refill goroutine, and Stop ends that goroutine
+	// instead of leaving it ranging over time.Tick forever.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Fill the burstLimiter channel every 200 milliseconds to maintain the rate limit
-	go func() {
-		for t := range time.Tick(200 * time.Millisecond) {
-			burstLimiter <- t
-		}
-	}()
+	burstLimiter := ratelimit.NewBurstLimiter(ctx, 200*time.Millisecond, 3)
 
 	for req := range requests {
-		<-burstLimiter // Wait for a token from burstLimiter
+		// Wait for a token from burstLimiter
+		if err := burstLimiter.Wait(ctx); err != nil {
+			fmt.Println("Request", req, "dropped:", err)
+			continue
+		}
 		fmt.Println("Request", req, "processed at", time.Now())
 	}
 
+	// Stop the refill goroutine and wait for it to return
+	burstLimiter.Stop()
+
+	fmt.Println("Goroutines before:", goroutinesBefore, "after:", runtime.NumGoroutine())
+
 }
@@ -2,35 +2,116 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
 
-func processRequest(ctx context.Context, req int) {
+type Status string
+
+const (
+	Processed Status = "processed"
+	TimedOut  Status = "timed out"
+	Cancelled Status = "cancelled"
+)
+
+type Request struct {
+	ID   int
+	Work time.Duration // how long the request takes to process
+}
+
+type Result struct {
+	Request int
+	Status  Status
+	Err     error // context.DeadlineExceeded or context.Canceled when not processed
+	At      time.Time
+}
+
+// processRequest handles one request under ctx, which is derived from the parent context of the whole batch
+func processRequest(ctx context.Context, req Request) Result {
 	select {
 	case <-ctx.Done():
-		fmt.Println("Request", req, "cancelled at", time.Now())
-	case <-time.After(200 * time.Millisecond):
-		fmt.Println("Request", req, "processed at", time.Now())
+		return contextResult(req.ID, ctx.Err())
+	case <-time.After(req.Work):
+		return Result{Request: req.ID, Status: Processed, At: time.Now()}
 	}
 }
 
+// contextResult turns a context error into a timed out or cancelled result
+func contextResult(id int, err error) Result {
+	status := Cancelled
+	if errors.Is(err, context.DeadlineExceeded) {
+		status = TimedOut
+	}
+	return Result{Request: id, Status: status, Err: err, At: time.Now()}
+}
+
+// processBatch processes the requests one by one; once parent is done, the queued requests are aborted too
+func processBatch(parent context.Context, requests <-chan Request, perRequest time.Duration, afterEach func(Result)) []Result {
+	var report []Result
+	for req := range requests {
+		var result Result
+		if err := parent.Err(); err != nil {
+			result = contextResult(req.ID, err) // Not started: the whole batch is already done
+		} else {
+			ctx, cancel := context.WithTimeout(parent, perRequest)
+			result = processRequest(ctx, req)
+			cancel()
+		}
+		report = append(report, result)
+		if afterEach != nil {
+			afterEach(result)
+		}
+	}
+	return report
+}
+
+func printReport(report []Result) {
+	counts := map[Status]int{}
+	for _, r := range report {
+		counts[r.Status]++
+		if r.Err != nil {
+			fmt.Printf("Request %d %s at %s: %v\n", r.Request, r.Status, r.At.Format("15:04:05.000"), r.Err)
+		} else {
+			fmt.Printf("Request %d %s at %s\n", r.Request, r.Status, r.At.Format("15:04:05.000"))
+		}
+	}
+	fmt.Printf("Processed: %d, timed out: %d, cancelled: %d\n", counts[Processed], counts[TimedOut], counts[Cancelled])
+}
+
+func newRequests() chan Request {
+	requests := make(chan Request, 5)
+	work := []time.Duration{100, 200, 400, 100, 100}
+	for i, w := range work {
+		requests <- Request{ID: i + 1, Work: w * time.Millisecond}
+	}
+	close(requests)
+	return requests
+}
+
 func main() {
 
 	// Rate Limiting with context.Context
 	// Use context.Context to implement rate limiting, which allows for more control over request cancellation
 
-	requests := make(chan int, 5)
+	// One parent context for the whole batch with a 750ms deadline.
+	// Each request gets at most 300ms, derived from the parent, so request 3 times out on its own
+	// and request 5 times out because the batch deadline has passed.
+	parent, cancel := context.WithTimeout(context.Background(), 750*time.Millisecond)
+	report := processBatch(parent, newRequests(), 300*time.Millisecond, nil)
+	cancel()
+	printReport(report)
 
-	for i := 1; i <= 5; i++ {
-		requests <- i
-	}
-	close(requests)
+	fmt.Println("-----------------------------------------------------------------------------------")
 
-	for req := range requests {
-		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-		processRequest(ctx, req)
-		cancel()
-	}
+	// Cancelling the parent context aborts every request still in the queue
+	parent, cancel = context.WithCancel(context.Background())
+	report = processBatch(parent, newRequests(), 300*time.Millisecond, func(r Result) {
+		if r.Request == 2 {
+			cancel()
+		}
+	})
+	cancel()
+	printReport(report)
 
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_sample_examples/024_rate_limiter/ratelimit"
+)
+
+func main() {
+
+	// Interchangeable Rate Limiting Strategies
+	// Every strategy implements ratelimit.Limiter and is selected by a ratelimit.Config,
+	// so the algorithm can be changed without touching the code that uses it
+
+	strategies := []ratelimit.Strategy{
+		ratelimit.StrategyInterval,
+		ratelimit.StrategyTokenBucket,
+		ratelimit.StrategyLeakyBucket,
+		ratelimit.StrategySlidingLog,
+		ratelimit.StrategySlidingCounter,
+	}
+
+	// 5 requests per second, with a burst of 3 where the strategy supports it
+	for _, strategy := range strategies {
+		limiter, err := ratelimit.New(ratelimit.Config{Strategy: strategy, Limit: 5, Per: time.Second, Burst: 3})
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+
+		fmt.Println("Strategy:", strategy)
+		start := time.Now()
+		for req := 1; req <= 6; req++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				fmt.Printf("  Request %d rejected: %v\n", req, err)
+				continue
+			}
+			fmt.Printf("  Request %d processed after %v\n", req, time.Since(start).Round(10*time.Millisecond))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"go_sample_examples/024_rate_limiter/ratelimit"
+)
+
+func main() {
+
+	// Rate Limiting per Client
+	// Each API key gets its own token bucket (2 requests per second with a burst of 3),
+	// so one busy client cannot use up the limit of the others
+
+	limiter := ratelimit.NewKeyedTokenBucket[string](2, 3, ratelimit.KeyedConfig{
+		MaxKeys: 2,                      // keep at most 2 clients in memory
+		IdleTTL: 500 * time.Millisecond, // forget clients idle for this long
+	})
+
+	requests := []string{"alice", "alice", "alice", "alice", "bob", "alice", "bob"}
+
+	for i, key := range requests {
+		if limiter.Allow(key) {
+			fmt.Printf("Request %d from %s allowed\n", i+1, key)
+		} else {
+			fmt.Printf("Request %d from %s rejected\n", i+1, key)
+		}
+	}
+
+	for _, key := range []string{"alice", "bob"} {
+		stats, _ := limiter.Stats(key)
+		fmt.Printf("%s: allowed %d, rejected %d\n", key, stats.Allowed, stats.Rejected)
+	}
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+
+	// A third client pushes out the least recently used one
+	limiter.Allow("carol")
+	_, aliceTracked := limiter.Stats("alice")
+	fmt.Println("Tracked clients:", limiter.Len(), "- alice still tracked:", aliceTracked)
+
+	// Idle clients are evicted after the TTL
+	time.Sleep(600 * time.Millisecond)
+	limiter.Sweep()
+	fmt.Println("Tracked clients after idling:", limiter.Len())
+}
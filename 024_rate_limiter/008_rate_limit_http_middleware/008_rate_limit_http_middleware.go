@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"go_sample_examples/024_rate_limiter/ratelimit"
+)
+
+func helloHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "Hello!")
+}
+
+// send runs a request through the handler in memory and prints the response
+func send(handler http.Handler, remoteAddr string) {
+	req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	fmt.Printf("%s -> %d %s, X-RateLimit-Remaining: %s, Retry-After: %q\n",
+		remoteAddr, rec.Code, http.StatusText(rec.Code),
+		rec.Header().Get("X-RateLimit-Remaining"), rec.Header().Get("Retry-After"))
+}
+
+func main() {
+
+	// Rate Limiting HTTP Middleware
+	// Requests over the limit are rejected with 429 Too Many Requests and the
+	// Retry-After and X-RateLimit-Remaining headers; httptest runs everything without a network
+
+	// One global limit: 1 request per second with a burst of 2
+	global := ratelimit.Middleware(ratelimit.NewTokenBucket(1, 2))(http.HandlerFunc(helloHandler))
+
+	for i := 0; i < 3; i++ {
+		send(global, "10.0.0.1:1234")
+	}
+
+	fmt.Println("-----------------------------------------------------------------------------------")
+
+	// A separate limit per client IP: 2 requests every 10 seconds
+	perClient := ratelimit.NewKeyed(func(string) ratelimit.Limiter {
+		return ratelimit.NewSlidingLog(2, 10*time.Second)
+	}, ratelimit.KeyedConfig{MaxKeys: 1000, IdleTTL: time.Minute})
+
+	keyed := ratelimit.KeyedMiddleware(perClient, ratelimit.ClientIP)(http.HandlerFunc(helloHandler))
+
+	send(keyed, "10.0.0.1:1234")
+	send(keyed, "10.0.0.1:1234")
+	send(keyed, "10.0.0.1:1234")
+	send(keyed, "10.0.0.2:5678") // A different client still has its full limit
+}
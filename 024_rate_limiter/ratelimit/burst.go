@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+// BurstLimiter is the buffered-channel limiter from the burst capacity
+// example: the channel starts full, so up to burst events pass immediately,
+// and a goroutine adds a token every interval. Unlike ranging over time.Tick,
+// the goroutine stops when Stop is called or the context is cancelled.
+type BurstLimiter struct {
+	tokens chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewBurstLimiter starts a burst limiter. The refill goroutine runs until
+// Stop is called or ctx is done.
+func NewBurstLimiter(ctx context.Context, interval time.Duration, burst int, opts ...Option) *BurstLimiter {
+	o := newOptions(opts)
+	burst = max(burst, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	l := &BurstLimiter{
+		tokens: make(chan struct{}, burst),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	// Initially fill the channel to allow a burst
+	for i := 0; i < burst; i++ {
+		l.tokens <- struct{}{}
+	}
+
+	// Start the ticker here so a fake clock sees it as soon as we return
+	go l.refill(ctx, o.clock.NewTicker(interval))
+	return l
+}
+
+func (l *BurstLimiter) refill(ctx context.Context, ticker clock.Ticker) {
+	defer close(l.done)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			// Drop the token when the bucket is full instead of blocking
+			select {
+			case l.tokens <- struct{}{}:
+			default:
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Allow implements Limiter.
+func (l *BurstLimiter) Allow() bool {
+	select {
+	case <-l.tokens:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait implements Limiter. It returns ErrLimiterStopped once the limiter has
+// been stopped and no tokens are left.
+func (l *BurstLimiter) Wait(ctx context.Context) error {
+	select {
+	case <-l.tokens:
+		return nil
+	default:
+	}
+	select {
+	case <-l.tokens:
+		return nil
+	case <-l.done:
+		return ErrLimiterStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// State implements Stater. RetryAfter is unknown and left at zero.
+func (l *BurstLimiter) State() State {
+	return State{Remaining: len(l.tokens)}
+}
+
+// Stop ends the refill goroutine and waits for it to return. It is safe to
+// call more than once.
+func (l *BurstLimiter) Stop() {
+	l.cancel()
+	<-l.done
+}
+
+// Done is closed once the refill goroutine has returned.
+func (l *BurstLimiter) Done() <-chan struct{} {
+	return l.done
+}
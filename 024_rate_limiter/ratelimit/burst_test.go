@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+// checkGoroutines fails t if the number of goroutines does not come back
+// down to baseline. A goroutine that closed its done channel may still be
+// returning, so the count is given a moment to settle.
+func checkGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running, want %d", runtime.NumGoroutine(), baseline)
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestBurstLimiterRefill(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	l := NewBurstLimiter(context.Background(), 200*time.Millisecond, 3, WithClock(fake))
+	defer l.Stop()
+
+	for i := range 3 {
+		if !l.Allow() {
+			t.Fatalf("event %d of the burst was not allowed", i+1)
+		}
+	}
+	if l.Allow() {
+		t.Fatal("allowed an event beyond the burst")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- l.Wait(context.Background()) }()
+	fake.Advance(200 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("Wait after a refill: %v", err)
+	}
+
+	// The bucket never holds more than the burst
+	fake.Advance(time.Second)
+	if got := l.State().Remaining; got > 3 {
+		t.Fatalf("Remaining = %d after a long pause, want at most 3", got)
+	}
+}
+
+func TestBurstLimiterStop(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	l := NewBurstLimiter(context.Background(), time.Hour, 1, WithClock(clock.NewFake(time.Time{})))
+	l.Allow()
+
+	waiting := make(chan error, 1)
+	go func() { waiting <- l.Wait(context.Background()) }()
+
+	l.Stop()
+	l.Stop() // safe to call twice
+	select {
+	case <-l.Done():
+	default:
+		t.Fatal("Done is not closed after Stop")
+	}
+	if err := <-waiting; err != ErrLimiterStopped {
+		t.Fatalf("Wait during Stop = %v, want %v", err, ErrLimiterStopped)
+	}
+	if err := l.Wait(context.Background()); err != ErrLimiterStopped {
+		t.Fatalf("Wait after Stop = %v, want %v", err, ErrLimiterStopped)
+	}
+	checkGoroutines(t, baseline)
+}
+
+func TestBurstLimiterContextCancel(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	l := NewBurstLimiter(ctx, time.Hour, 1, WithClock(clock.NewFake(time.Time{})))
+
+	cancel()
+	select {
+	case <-l.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done is not closed after the context was cancelled")
+	}
+	checkGoroutines(t, baseline)
+}
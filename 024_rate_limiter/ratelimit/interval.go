@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Interval allows one event per interval with no burst, like ranging over
+// time.Tick or time.NewTicker in the basic examples, but without a ticker
+// goroutine running in the background.
+type Interval struct {
+	mu       sync.Mutex
+	clock    Clock
+	interval time.Duration
+	next     time.Time
+}
+
+// NewInterval returns a limiter that spaces events at least interval apart.
+func NewInterval(interval time.Duration, opts ...Option) *Interval {
+	o := newOptions(opts)
+	return &Interval{clock: o.clock, interval: interval, next: o.clock.Now()}
+}
+
+func (l *Interval) try() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	if now.Before(l.next) {
+		return l.next.Sub(now)
+	}
+	l.next = now.Add(l.interval)
+	return 0
+}
+
+// Allow implements Limiter.
+func (l *Interval) Allow() bool {
+	return l.try() == 0
+}
+
+// Wait implements Limiter.
+func (l *Interval) Wait(ctx context.Context) error {
+	return waitFor(ctx, l.clock, l.try)
+}
+
+// State implements Stater.
+func (l *Interval) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if wait := l.next.Sub(l.clock.Now()); wait > 0 {
+		return State{RetryAfter: wait}
+	}
+	return State{Remaining: 1}
+}
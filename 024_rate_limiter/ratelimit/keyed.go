@@ -0,0 +1,199 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// KeyedConfig bounds the memory used by a Keyed limiter.
+type KeyedConfig struct {
+	MaxKeys int           // evict the least recently used key beyond this; 0 means no limit
+	IdleTTL time.Duration // evict keys unused for this long; 0 means never
+}
+
+// KeyStats describes the traffic seen for one key.
+type KeyStats struct {
+	Allowed  int
+	Rejected int
+	Created  time.Time
+	LastSeen time.Time
+}
+
+type keyedEntry[K comparable] struct {
+	key     K
+	limiter Limiter
+	stats   KeyStats
+}
+
+// Keyed keeps a separate limiter for every key, such as an API key or a
+// client IP address. Limiters are created on first use and evicted when idle
+// or when the registry is full.
+type Keyed[K comparable] struct {
+	mu      sync.Mutex
+	clock   Clock
+	cfg     KeyedConfig
+	factory func(K) Limiter
+	entries map[K]*list.Element
+	lru     *list.List // front is the most recently used key
+}
+
+// NewKeyed returns a registry that builds the limiter for a new key with
+// factory.
+func NewKeyed[K comparable](factory func(K) Limiter, cfg KeyedConfig, opts ...Option) *Keyed[K] {
+	o := newOptions(opts)
+	return &Keyed[K]{
+		clock:   o.clock,
+		cfg:     cfg,
+		factory: factory,
+		entries: make(map[K]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// NewKeyedTokenBucket returns a registry with a token bucket of the given
+// rate and burst for every key.
+func NewKeyedTokenBucket[K comparable](rate float64, burst int, cfg KeyedConfig, opts ...Option) *Keyed[K] {
+	return NewKeyed(func(K) Limiter { return NewTokenBucket(rate, burst, opts...) }, cfg, opts...)
+}
+
+// get returns the entry for key, creating it if needed. It must be called
+// with mu held.
+func (k *Keyed[K]) get(key K, now time.Time) *keyedEntry[K] {
+	k.evict(now)
+	if el, ok := k.entries[key]; ok {
+		k.lru.MoveToFront(el)
+		e := el.Value.(*keyedEntry[K])
+		e.stats.LastSeen = now
+		return e
+	}
+
+	e := &keyedEntry[K]{key: key, limiter: k.factory(key), stats: KeyStats{Created: now, LastSeen: now}}
+	k.entries[key] = k.lru.PushFront(e)
+	if k.cfg.MaxKeys > 0 && k.lru.Len() > k.cfg.MaxKeys {
+		k.remove(k.lru.Back())
+	}
+	return e
+}
+
+// evict drops keys that have been idle longer than IdleTTL. It must be called
+// with mu held.
+func (k *Keyed[K]) evict(now time.Time) {
+	if k.cfg.IdleTTL <= 0 {
+		return
+	}
+	for el := k.lru.Back(); el != nil; el = k.lru.Back() {
+		if now.Sub(el.Value.(*keyedEntry[K]).stats.LastSeen) < k.cfg.IdleTTL {
+			return
+		}
+		k.remove(el)
+	}
+}
+
+// stopper is implemented by limiters that run a goroutine, such as
+// BurstLimiter.
+type stopper interface {
+	Stop()
+}
+
+// remove forgets a key and stops its limiter if it has a goroutine. A Wait
+// still blocked on that limiter returns ErrLimiterStopped. It must be called
+// with mu held.
+func (k *Keyed[K]) remove(el *list.Element) {
+	k.lru.Remove(el)
+	e := el.Value.(*keyedEntry[K])
+	delete(k.entries, e.key)
+	if s, ok := e.limiter.(stopper); ok {
+		s.Stop()
+	}
+}
+
+// Allow reports whether an event for key may happen now.
+func (k *Keyed[K]) Allow(key K) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e := k.get(key, k.clock.Now())
+	ok := e.limiter.Allow()
+	if ok {
+		e.stats.Allowed++
+	} else {
+		e.stats.Rejected++
+	}
+	return ok
+}
+
+// Wait blocks until an event for key may happen or ctx is done.
+func (k *Keyed[K]) Wait(ctx context.Context, key K) error {
+	k.mu.Lock()
+	e := k.get(key, k.clock.Now())
+	k.mu.Unlock()
+
+	err := e.limiter.Wait(ctx)
+
+	k.mu.Lock()
+	if err == nil {
+		e.stats.Allowed++
+	} else {
+		e.stats.Rejected++
+	}
+	k.mu.Unlock()
+	return err
+}
+
+// State returns the state of the limiter for key, creating the limiter if
+// needed. Limiters that are not a Stater always report one remaining event.
+func (k *Keyed[K]) State(key K) State {
+	k.mu.Lock()
+	e := k.get(key, k.clock.Now())
+	k.mu.Unlock()
+	if s, ok := e.limiter.(Stater); ok {
+		return s.State()
+	}
+	return State{Remaining: 1}
+}
+
+// Stats returns the statistics for key and whether the key is tracked.
+func (k *Keyed[K]) Stats(key K) (KeyStats, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	el, ok := k.entries[key]
+	if !ok {
+		return KeyStats{}, false
+	}
+	return el.Value.(*keyedEntry[K]).stats, true
+}
+
+// Snapshot returns the statistics of every tracked key.
+func (k *Keyed[K]) Snapshot() map[K]KeyStats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	out := make(map[K]KeyStats, len(k.entries))
+	for key, el := range k.entries {
+		out[key] = el.Value.(*keyedEntry[K]).stats
+	}
+	return out
+}
+
+// Len returns the number of tracked keys.
+func (k *Keyed[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lru.Len()
+}
+
+// Sweep evicts idle keys now instead of waiting for the next call.
+func (k *Keyed[K]) Sweep() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.evict(k.clock.Now())
+}
+
+// Stop forgets every key and stops the limiters that run a goroutine.
+func (k *Keyed[K]) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for el := k.lru.Back(); el != nil; el = k.lru.Back() {
+		k.remove(el)
+	}
+}
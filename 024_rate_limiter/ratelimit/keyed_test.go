@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+func TestKeyedSeparateLimits(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	k := NewKeyedTokenBucket[string](1, 2, KeyedConfig{}, WithClock(fake))
+
+	for _, key := range []string{"alice", "bob"} {
+		for i := range 2 {
+			if !k.Allow(key) {
+				t.Fatalf("%s: event %d of the burst was not allowed", key, i+1)
+			}
+		}
+		if k.Allow(key) {
+			t.Fatalf("%s: allowed an event beyond the burst", key)
+		}
+	}
+
+	fake.Advance(time.Second)
+	if !k.Allow("alice") {
+		t.Fatal("alice: not allowed after the bucket refilled")
+	}
+	if stats, ok := k.Stats("bob"); !ok || stats.Allowed != 2 || stats.Rejected != 1 {
+		t.Fatalf("bob: Stats = %+v, %v, want 2 allowed and 1 rejected", stats, ok)
+	}
+}
+
+func TestKeyedEviction(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     KeyedConfig
+		idle    time.Duration // time between touching a and touching b, c
+		want    []string      // keys still held
+		evicted []string
+	}{
+		{"max keys evicts the least recently used", KeyedConfig{MaxKeys: 2}, 0, []string{"b", "c"}, []string{"a"}},
+		{"idle ttl evicts unused keys", KeyedConfig{IdleTTL: time.Minute}, time.Minute, []string{"b", "c"}, []string{"a"}},
+		{"idle ttl keeps recent keys", KeyedConfig{IdleTTL: time.Minute}, 30 * time.Second, []string{"a", "b", "c"}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := clock.NewFake(time.Time{})
+			k := NewKeyedTokenBucket[string](1, 1, tc.cfg, WithClock(fake))
+
+			k.Allow("a")
+			fake.Advance(tc.idle)
+			k.Allow("b")
+			k.Allow("c")
+
+			for _, key := range tc.want {
+				if _, ok := k.Stats(key); !ok {
+					t.Errorf("key %s was evicted", key)
+				}
+			}
+			for _, key := range tc.evicted {
+				if _, ok := k.Stats(key); ok {
+					t.Errorf("key %s was kept", key)
+				}
+			}
+			if k.Len() != len(tc.want) {
+				t.Errorf("Len = %d, want %d", k.Len(), len(tc.want))
+			}
+		})
+	}
+}
+
+func TestKeyedStopsEvictedLimiters(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	fake := clock.NewFake(time.Time{})
+	k := NewKeyed(func(string) Limiter {
+		return NewBurstLimiter(context.Background(), time.Second, 1, WithClock(fake))
+	}, KeyedConfig{MaxKeys: 2, IdleTTL: time.Minute}, WithClock(fake))
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		k.Allow(key)
+	}
+	// a and b were evicted for MaxKeys; only the refill goroutines of c and d run
+	if n := fake.Pending(); n != 2 {
+		t.Fatalf("%d refill tickers running, want 2", n)
+	}
+	checkGoroutines(t, baseline+2)
+
+	fake.Advance(time.Minute)
+	k.Sweep()
+	checkGoroutines(t, baseline)
+
+	k.Allow("e")
+	k.Stop()
+	checkGoroutines(t, baseline)
+	if k.Len() != 0 {
+		t.Fatalf("Len = %d after Stop, want 0", k.Len())
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LeakyBucket lets events out at a constant pace of one per interval. Up to
+// capacity callers may queue in Wait; further callers are rejected, so bursts
+// are smoothed out instead of passed through.
+type LeakyBucket struct {
+	mu       sync.Mutex
+	clock    Clock
+	interval time.Duration
+	capacity int
+	next     time.Time // when the next slot leaks out
+}
+
+// NewLeakyBucket returns a leaky bucket with the given pace and queue size.
+// The interval is at least one nanosecond.
+func NewLeakyBucket(interval time.Duration, capacity int, opts ...Option) *LeakyBucket {
+	o := newOptions(opts)
+	return &LeakyBucket{clock: o.clock, interval: max(interval, 1), capacity: max(capacity, 1), next: o.clock.Now()}
+}
+
+// Allow implements Limiter. It only succeeds when no one is queued.
+func (b *LeakyBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if now.Before(b.next) {
+		return false
+	}
+	b.next = now.Add(b.interval)
+	return true
+}
+
+// Wait implements Limiter. It returns ErrLimitExceeded when the queue is
+// full.
+func (b *LeakyBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	now := b.clock.Now()
+	slot := b.next
+	if slot.Before(now) {
+		slot = now
+	}
+	if queued := int(slot.Sub(now) / b.interval); queued >= b.capacity {
+		b.mu.Unlock()
+		return ErrLimitExceeded
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(slot) {
+		b.mu.Unlock()
+		return ErrWouldExceedDeadline
+	}
+	b.next = slot.Add(b.interval)
+	b.mu.Unlock()
+
+	if delay := slot.Sub(now); delay > 0 {
+		return sleep(ctx, b.clock, delay)
+	}
+	return nil
+}
+
+// State implements Stater. It describes Allow, which only succeeds when no
+// one is queued.
+func (b *LeakyBucket) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if wait := b.next.Sub(b.clock.Now()); wait > 0 {
+		return State{RetryAfter: wait}
+	}
+	return State{Remaining: 1}
+}
@@ -0,0 +1,143 @@
+// Package ratelimit provides reusable rate limiters built on the ideas shown
+// in 024_rate_limiter. Every strategy implements the Limiter interface and can
+// be selected at runtime through a Config.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+// ErrLimitExceeded is returned by Wait when a limiter rejects the event
+// outright instead of making the caller wait.
+var ErrLimitExceeded = errors.New("ratelimit: limit exceeded")
+
+// ErrLimiterStopped is returned by Wait after a limiter has been stopped.
+var ErrLimiterStopped = errors.New("ratelimit: limiter stopped")
+
+// Limiter is implemented by every rate-limiting strategy in this package.
+type Limiter interface {
+	// Allow reports whether an event may happen now and records it if so.
+	Allow() bool
+	// Wait blocks until an event may happen or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// State describes how much room a limiter has left.
+type State struct {
+	Remaining  int           // events allowed right now
+	RetryAfter time.Duration // time until the next event is allowed when Remaining is 0
+}
+
+// Stater is implemented by limiters that can report their State. All of the
+// limiters in this package implement it.
+type Stater interface {
+	State() State
+}
+
+// Clock tells the limiters what time it is and lets them wait. Tests can
+// replace the real clock with a clock.Fake they advance by hand.
+type Clock = clock.Clock
+
+type options struct {
+	clock Clock
+}
+
+// Option configures a limiter.
+type Option func(*options)
+
+// WithClock makes the limiter read time from c instead of the system clock.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Strategy names a rate-limiting algorithm.
+type Strategy string
+
+const (
+	StrategyInterval       Strategy = "interval"        // one event every Per/Limit, like time.Tick
+	StrategyTokenBucket    Strategy = "token_bucket"    // Limit events per Per with bursts of Burst
+	StrategyLeakyBucket    Strategy = "leaky_bucket"    // evenly spaced events with up to Burst waiting
+	StrategySlidingLog     Strategy = "sliding_log"     // at most Limit events in any window of Per
+	StrategySlidingCounter Strategy = "sliding_counter" // approximate sliding window from two counters
+)
+
+// Config selects and parameterises a limiter, so the strategy can come from a
+// configuration file.
+type Config struct {
+	Strategy Strategy      `json:"strategy"`
+	Limit    int           `json:"limit"` // events allowed per Per
+	Per      time.Duration `json:"per"`
+	Burst    int           `json:"burst"` // bucket size for token and leaky buckets
+}
+
+// New builds the limiter described by cfg.
+func New(cfg Config, opts ...Option) (Limiter, error) {
+	if cfg.Limit <= 0 || cfg.Per <= 0 {
+		return nil, fmt.Errorf("ratelimit: limit and per must be positive, got %d per %v", cfg.Limit, cfg.Per)
+	}
+	interval := cfg.Per / time.Duration(cfg.Limit)
+	if interval == 0 {
+		return nil, fmt.Errorf("ratelimit: %d per %v is faster than one event per nanosecond", cfg.Limit, cfg.Per)
+	}
+
+	switch cfg.Strategy {
+	case StrategyInterval:
+		return NewInterval(interval, opts...), nil
+	case StrategyTokenBucket:
+		return NewTokenBucket(float64(cfg.Limit)/cfg.Per.Seconds(), cfg.Burst, opts...), nil
+	case StrategyLeakyBucket:
+		return NewLeakyBucket(interval, cfg.Burst, opts...), nil
+	case StrategySlidingLog:
+		return NewSlidingLog(cfg.Limit, cfg.Per, opts...), nil
+	case StrategySlidingCounter:
+		return NewSlidingCounter(cfg.Limit, cfg.Per, opts...), nil
+	}
+	return nil, fmt.Errorf("ratelimit: unknown strategy %q", cfg.Strategy)
+}
+
+// waitFor retries try until it succeeds or ctx is done. try returns 0 when
+// the event was allowed, or how long to wait before trying again.
+func waitFor(ctx context.Context, c Clock, try func() time.Duration) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		delay := try()
+		if delay == 0 {
+			return nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(c.Now().Add(delay)) {
+			return ErrWouldExceedDeadline
+		}
+		if err := sleep(ctx, c, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d on c, or until ctx is done.
+func sleep(ctx context.Context, c Clock, d time.Duration) error {
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
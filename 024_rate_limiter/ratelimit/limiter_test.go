@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"zero limit", Config{Strategy: StrategyInterval, Limit: 0, Per: time.Second}},
+		{"negative per", Config{Strategy: StrategyInterval, Limit: 5, Per: -time.Second}},
+		{"interval below a nanosecond", Config{Strategy: StrategyLeakyBucket, Limit: 2e9, Per: time.Second}},
+		{"unknown strategy", Config{Strategy: "fixed_window", Limit: 5, Per: time.Second}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if l, err := New(tc.cfg); err == nil {
+				t.Fatalf("New(%+v) = %T, want an error", tc.cfg, l)
+			}
+		})
+	}
+}
+
+// strategyTests describe every strategy configured for 5 events per second.
+var strategyTests = []struct {
+	strategy Strategy
+	burst    int
+	allowed  int           // events allowed at the same instant
+	wait     time.Duration // how long Wait blocks once they are used up
+}{
+	{StrategyInterval, 0, 1, 200 * time.Millisecond},
+	{StrategyTokenBucket, 3, 3, 200 * time.Millisecond},
+	{StrategyLeakyBucket, 3, 1, 200 * time.Millisecond},
+	{StrategySlidingLog, 0, 5, time.Second},
+	{StrategySlidingCounter, 0, 5, time.Second},
+}
+
+func newFakeLimiter(t *testing.T, strategy Strategy, burst int) (Limiter, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(time.Time{})
+	l, err := New(Config{Strategy: strategy, Limit: 5, Per: time.Second, Burst: burst}, WithClock(fake))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return l, fake
+}
+
+func TestAllow(t *testing.T) {
+	for _, tc := range strategyTests {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			l, fake := newFakeLimiter(t, tc.strategy, tc.burst)
+
+			allowed := 0
+			for range 10 {
+				if l.Allow() {
+					allowed++
+				}
+			}
+			if allowed != tc.allowed {
+				t.Errorf("allowed %d of 10 at once, want %d", allowed, tc.allowed)
+			}
+
+			fake.Advance(tc.wait - time.Millisecond)
+			if l.Allow() {
+				t.Errorf("allowed 1ms before %v had passed", tc.wait)
+			}
+			fake.Advance(10 * time.Millisecond)
+			if !l.Allow() {
+				t.Errorf("not allowed once %v had passed", tc.wait)
+			}
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	for _, tc := range strategyTests {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			l, fake := newFakeLimiter(t, tc.strategy, tc.burst)
+			for range tc.allowed {
+				if err := l.Wait(context.Background()); err != nil {
+					t.Fatalf("Wait within the limit: %v", err)
+				}
+			}
+
+			start := fake.Now()
+			done := make(chan error, 1)
+			go func() { done <- l.Wait(context.Background()) }()
+
+			// Step the clock whenever Wait is sleeping on it
+			for fake.Since(start) < 5*time.Second {
+				select {
+				case err := <-done:
+					if err != nil {
+						t.Fatalf("Wait: %v", err)
+					}
+					if waited := fake.Since(start); waited < tc.wait || waited > tc.wait+20*time.Millisecond {
+						t.Fatalf("Wait returned after %v, want %v", waited, tc.wait)
+					}
+					return
+				default:
+				}
+				if fake.Pending() > 0 {
+					fake.Advance(10 * time.Millisecond)
+				} else {
+					runtime.Gosched()
+				}
+			}
+			t.Fatal("Wait did not return after 5s")
+		})
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	for _, tc := range strategyTests {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			l, fake := newFakeLimiter(t, tc.strategy, tc.burst)
+			for range tc.allowed {
+				l.Allow()
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- l.Wait(ctx) }()
+			fake.BlockUntil(1)
+			cancel()
+			if err := <-done; err != context.Canceled {
+				t.Fatalf("Wait = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestWaitWouldExceedDeadline(t *testing.T) {
+	for _, tc := range strategyTests {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			l, fake := newFakeLimiter(t, tc.strategy, tc.burst)
+			for range tc.allowed {
+				l.Allow()
+			}
+
+			ctx, cancel := clock.WithTimeout(context.Background(), fake, tc.wait/2)
+			defer cancel()
+			if err := l.Wait(ctx); err != ErrWouldExceedDeadline {
+				t.Fatalf("Wait = %v, want %v", err, ErrWouldExceedDeadline)
+			}
+		})
+	}
+}
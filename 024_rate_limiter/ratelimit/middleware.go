@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware rejects requests over the limit of l with 429 Too Many Requests.
+// Every response carries an X-RateLimit-Remaining header, and rejected ones a
+// Retry-After header, when l implements Stater.
+func Middleware(l Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed := l.Allow()
+			var state *State
+			if s, ok := l.(Stater); ok {
+				st := s.State()
+				state = &st
+			}
+			serve(w, r, next, allowed, state)
+		})
+	}
+}
+
+// KeyedMiddleware applies a separate limit to every key returned by key, such
+// as ClientIP or an API key header.
+func KeyedMiddleware(k *Keyed[string], key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := key(r)
+			allowed := k.Allow(id)
+			state := k.State(id)
+			serve(w, r, next, allowed, &state)
+		})
+	}
+}
+
+func serve(w http.ResponseWriter, r *http.Request, next http.Handler, allowed bool, state *State) {
+	if state != nil {
+		remaining := state.Remaining
+		if !allowed {
+			remaining = 0
+		}
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	}
+	if allowed {
+		next.ServeHTTP(w, r)
+		return
+	}
+	if state != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(state.RetryAfter)))
+	}
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+// retryAfterSeconds rounds d up to whole seconds, as Retry-After requires.
+func retryAfterSeconds(d time.Duration) int {
+	return max(int(math.Ceil(d.Seconds())), 1)
+}
+
+// ClientIP returns the host part of the request's remote address.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+type wantResponse struct {
+	status     int
+	remaining  string // X-RateLimit-Remaining, "" when absent
+	retryAfter string // Retry-After, "" when absent
+}
+
+func checkResponse(t *testing.T, name string, rec *httptest.ResponseRecorder, want wantResponse) {
+	t.Helper()
+	if rec.Code != want.status {
+		t.Errorf("%s: status = %d, want %d", name, rec.Code, want.status)
+	}
+	if got := rec.Header().Get("X-RateLimit-Remaining"); got != want.remaining {
+		t.Errorf("%s: X-RateLimit-Remaining = %q, want %q", name, got, want.remaining)
+	}
+	if got := rec.Header().Get("Retry-After"); got != want.retryAfter {
+		t.Errorf("%s: Retry-After = %q, want %q", name, got, want.retryAfter)
+	}
+}
+
+// noState is a limiter that does not implement Stater.
+type noState struct{ allow bool }
+
+func (l noState) Allow() bool                { return l.allow }
+func (l noState) Wait(context.Context) error { return nil }
+
+func TestMiddleware(t *testing.T) {
+	// Two events at once, then one every two seconds
+	fake := clock.NewFake(time.Time{})
+	h := Middleware(NewTokenBucket(0.5, 2, WithClock(fake)))(okHandler)
+
+	for i, want := range []wantResponse{
+		{http.StatusOK, "1", ""},
+		{http.StatusOK, "0", ""},
+		{http.StatusTooManyRequests, "0", "2"},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		checkResponse(t, fmt.Sprintf("request %d", i+1), rec, want)
+	}
+
+	// Retry-After rounds up to whole seconds
+	fake.Advance(1500 * time.Millisecond)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	checkResponse(t, "1.5s later", rec, wantResponse{http.StatusTooManyRequests, "0", "1"})
+
+	fake.Advance(500 * time.Millisecond)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	checkResponse(t, "2s later", rec, wantResponse{http.StatusOK, "0", ""})
+}
+
+func TestMiddlewareWithoutState(t *testing.T) {
+	tests := []struct {
+		allow bool
+		want  wantResponse
+	}{
+		{true, wantResponse{http.StatusOK, "", ""}},
+		{false, wantResponse{http.StatusTooManyRequests, "", ""}},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		Middleware(noState{tc.allow})(okHandler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		checkResponse(t, fmt.Sprintf("allow %v", tc.allow), rec, tc.want)
+	}
+}
+
+func TestKeyedMiddleware(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	k := NewKeyedTokenBucket[string](0.5, 1, KeyedConfig{}, WithClock(fake))
+	h := KeyedMiddleware(k, ClientIP)(okHandler)
+
+	tests := []struct {
+		remoteAddr string
+		want       wantResponse
+	}{
+		{"10.0.0.1:1234", wantResponse{http.StatusOK, "0", ""}},
+		{"10.0.0.1:5678", wantResponse{http.StatusTooManyRequests, "0", "2"}}, // same client, other port
+		{"10.0.0.2:1234", wantResponse{http.StatusOK, "0", ""}},               // separate limit
+		{"10.0.0.2:1234", wantResponse{http.StatusTooManyRequests, "0", "2"}},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		checkResponse(t, tc.remoteAddr, rec, tc.want)
+	}
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SlidingLog allows at most limit events in any window. It remembers the time
+// of every allowed event, which makes it exact but costs memory per event.
+type SlidingLog struct {
+	mu     sync.Mutex
+	clock  Clock
+	limit  int
+	window time.Duration
+	log    []time.Time
+}
+
+// NewSlidingLog returns a sliding-window-log limiter.
+func NewSlidingLog(limit int, window time.Duration, opts ...Option) *SlidingLog {
+	o := newOptions(opts)
+	return &SlidingLog{clock: o.clock, limit: max(limit, 1), window: window}
+}
+
+func (l *SlidingLog) try() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	if delay := l.peek(now); delay > 0 {
+		return delay
+	}
+	l.log = append(l.log, now)
+	return 0
+}
+
+// peek forgets events that have left the window and returns how long until
+// the next event is allowed. It must be called with mu held.
+func (l *SlidingLog) peek(now time.Time) time.Duration {
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(l.log) && !l.log[i].After(cutoff) {
+		i++
+	}
+	l.log = l.log[i:]
+
+	if len(l.log) >= l.limit {
+		return l.log[0].Add(l.window).Sub(now)
+	}
+	return 0
+}
+
+// Allow implements Limiter.
+func (l *SlidingLog) Allow() bool {
+	return l.try() == 0
+}
+
+// Wait implements Limiter.
+func (l *SlidingLog) Wait(ctx context.Context) error {
+	return waitFor(ctx, l.clock, l.try)
+}
+
+// State implements Stater.
+func (l *SlidingLog) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delay := l.peek(l.clock.Now())
+	return State{Remaining: l.limit - len(l.log), RetryAfter: delay}
+}
+
+// SlidingCounter approximates a sliding window with the counts of the current
+// and previous fixed windows, weighting the previous one by how much of it
+// still overlaps the sliding window. It uses constant memory.
+type SlidingCounter struct {
+	mu          sync.Mutex
+	clock       Clock
+	limit       int
+	window      time.Duration
+	start       time.Time // start of the current fixed window
+	prev, count int
+}
+
+// NewSlidingCounter returns a sliding-window-counter limiter.
+func NewSlidingCounter(limit int, window time.Duration, opts ...Option) *SlidingCounter {
+	o := newOptions(opts)
+	return &SlidingCounter{clock: o.clock, limit: max(limit, 1), window: window, start: o.clock.Now()}
+}
+
+func (c *SlidingCounter) try() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delay, _ := c.peek(c.clock.Now())
+	if delay == 0 {
+		c.count++
+	}
+	return delay
+}
+
+// peek rolls the windows forward to now and returns how long until the next
+// event is allowed and the estimated number of events in the sliding window.
+// It must be called with mu held.
+func (c *SlidingCounter) peek(now time.Time) (time.Duration, float64) {
+	// Roll the fixed windows forward
+	if elapsed := now.Sub(c.start); elapsed >= c.window {
+		if elapsed >= 2*c.window {
+			c.prev = 0
+		} else {
+			c.prev = c.count
+		}
+		c.count = 0
+		c.start = c.start.Add(elapsed / c.window * c.window)
+	}
+
+	elapsed := now.Sub(c.start)
+	weight := 1 - float64(elapsed)/float64(c.window)
+	estimate := float64(c.prev)*weight + float64(c.count)
+	if estimate < float64(c.limit) {
+		return 0, estimate
+	}
+
+	// Wait until the previous window has faded enough, or the current one ends
+	end := c.window - elapsed
+	if c.count >= c.limit || c.prev == 0 {
+		return end, estimate
+	}
+	need := 1 - float64(c.limit-c.count)/float64(c.prev)
+	wait := time.Duration(need*float64(c.window)) - elapsed
+	return min(max(wait, time.Millisecond), end), estimate
+}
+
+// Allow implements Limiter.
+func (c *SlidingCounter) Allow() bool {
+	return c.try() == 0
+}
+
+// Wait implements Limiter.
+func (c *SlidingCounter) Wait(ctx context.Context) error {
+	return waitFor(ctx, c.clock, c.try)
+}
+
+// State implements Stater.
+func (c *SlidingCounter) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delay, estimate := c.peek(c.clock.Now())
+	return State{Remaining: max(int(float64(c.limit)-estimate), 0), RetryAfter: delay}
+}
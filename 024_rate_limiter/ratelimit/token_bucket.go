@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrWouldExceedDeadline is returned by Wait when the context deadline comes
+// before the next token is available.
+var ErrWouldExceedDeadline = errors.New("ratelimit: wait would exceed context deadline")
+
+// TokenBucket is a token-bucket rate limiter shared by any number of
+// goroutines. The bucket holds up to burst tokens and refills at rate tokens
+// per second; every event takes one token.
+type TokenBucket struct {
+	mu     sync.Mutex
+	clock  Clock
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full bucket that allows rate events per second
+// with bursts of up to burst events. rate must be positive.
+func NewTokenBucket(rate float64, burst int, opts ...Option) *TokenBucket {
+	if rate <= 0 {
+		panic("ratelimit: rate must be positive")
+	}
+	o := newOptions(opts)
+	burst = max(burst, 1)
+	return &TokenBucket{
+		clock:  o.clock,
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   o.clock.Now(),
+	}
+}
+
+// advance refills the bucket up to now. It must be called with mu held.
+func (b *TokenBucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.burst), b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// Allow reports whether an event may happen now and takes a token if so.
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.clock.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// State implements Stater.
+func (b *TokenBucket) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.clock.Now())
+	if b.tokens >= 1 {
+		return State{Remaining: int(b.tokens)}
+	}
+	return State{RetryAfter: time.Duration((1 - b.tokens) / b.rate * float64(time.Second))}
+}
+
+// Reservation is a token taken ahead of time. The caller must wait until
+// TimeToAct before acting, or Cancel the reservation to give the token back.
+type Reservation struct {
+	bucket    *TokenBucket
+	timeToAct time.Time
+	cancelled bool
+}
+
+// Reserve takes a token and returns when it may be used. Unlike Allow it
+// always succeeds; the token may be borrowed from the future.
+func (b *TokenBucket) Reserve() *Reservation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.advance(now)
+	b.tokens--
+	r := &Reservation{bucket: b, timeToAct: now}
+	if b.tokens < 0 {
+		r.timeToAct = now.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+	return r
+}
+
+// TimeToAct returns the time at which the reserved event may happen.
+func (r *Reservation) TimeToAct() time.Time {
+	return r.timeToAct
+}
+
+// Delay returns how long the caller has to wait before acting.
+func (r *Reservation) Delay() time.Duration {
+	return max(r.timeToAct.Sub(r.bucket.clock.Now()), 0)
+}
+
+// Cancel gives the token back to the bucket.
+func (r *Reservation) Cancel() {
+	b := r.bucket
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.cancelled {
+		return
+	}
+	r.cancelled = true
+	b.advance(b.clock.Now())
+	b.tokens = math.Min(float64(b.burst), b.tokens+1)
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r := b.Reserve()
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(r.timeToAct) {
+		r.Cancel()
+		return ErrWouldExceedDeadline
+	}
+
+	if err := sleep(ctx, b.clock, delay); err != nil {
+		r.Cancel()
+		return err
+	}
+	return nil
+}
@@ -33,9 +33,10 @@ func main() {
 	go func() {
 		// CAS: If counter equals 100, set it to 200
 		if atomic.CompareAndSwapInt32(&counter, 100, 200) {
-			fmt.Println("CAS successful, new value:", counter)
+			// Read with atomic.LoadInt32: the other goroutines may still be writing counter
+			fmt.Println("CAS successful, new value:", atomic.LoadInt32(&counter))
 		} else {
-			fmt.Println("CAS failed, current value:", counter)
+			fmt.Println("CAS failed, current value:", atomic.LoadInt32(&counter))
 		}
 		wg.Done()
 	}()
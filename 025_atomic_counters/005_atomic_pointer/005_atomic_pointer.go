@@ -2,9 +2,20 @@ package main
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
+
+	"go_sample_examples/025_atomic_counters/config"
 )
 
+// ServerConfig is replaced as a whole and never modified in place
+type ServerConfig struct {
+	MaxConnections int
+	Timeout        time.Duration
+	Features       []string
+}
+
 func main() {
 
 	// Atomic Pointer
@@ -19,10 +30,94 @@ func main() {
 	fmt.Println("Current Value:", data.Load())
 
 	// Start a goroutine to change the value
+	stored := make(chan struct{})
 	go func() {
 		data.Store("New Value")
+		close(stored) // Signal that the new value is in place
 	}()
 
+	// Wait for the writer; loading right away would race with it and could still see the old value
+	<-stored
+
 	// Load and print the new value
 	fmt.Println("New Value:", data.Load())
+
+	fmt.Println("-----------------------------------")
+
+	// Copy-on-Write Config Holder
+	// config.Holder wraps a typed atomic.Pointer: readers Load without locks, writers publish a modified copy
+	// with compare-and-swap, every change gets a version, and subscribers are told about changes
+
+	holder := config.New(&ServerConfig{MaxConnections: 100, Timeout: time.Second})
+
+	changes, unsubscribe := holder.Subscribe()
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		for change := range changes {
+			// Only the latest unread change is kept, so a slow subscriber may skip versions
+			fmt.Printf("Reloaded version %d: MaxConnections %d -> %d\n",
+				change.Version, change.Old.MaxConnections, change.New.MaxConnections)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Readers on the hot path never take a lock
+	var reads atomic.Int64
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cfg := holder.Load()
+				_ = cfg.MaxConnections
+				reads.Add(1)
+			}
+		}()
+	}
+
+	// Concurrent writers each add 10 connections; CAS retries make sure no update is lost
+	var writers sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			holder.Update(func(old *ServerConfig) *ServerConfig {
+				next := *old // Copy, never modify old
+				next.MaxConnections += 10
+				return &next
+			})
+		}()
+	}
+	writers.Wait()
+
+	// Returning nil from Update leaves the config untouched
+	_, _, changed := holder.Update(func(old *ServerConfig) *ServerConfig {
+		if old.MaxConnections >= 200 {
+			return nil
+		}
+		next := *old
+		next.MaxConnections = 200
+		return &next
+	})
+	fmt.Println("Raised to 200:", changed)
+
+	close(stop)
+	wg.Wait()
+
+	// Unsubscribing closes the channel, but a change still buffered in it is delivered first,
+	// so once the subscriber is done it has printed the last change
+	unsubscribe()
+	<-reloaded
+
+	cfg, version := holder.Snapshot()
+	fmt.Printf("Final MaxConnections: %d at version %d\n", cfg.MaxConnections, version)
+	fmt.Println("Readers loaded the config concurrently:", reads.Load() > 0)
 }
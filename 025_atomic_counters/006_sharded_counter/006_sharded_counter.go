@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go_sample_examples/025_atomic_counters/counter"
+)
+
+func main() {
+
+	// Sharded Counter
+	// Many goroutines increment the same counter; each one writes to one of several padded shards,
+	// and the shards are only summed when the counter is read
+
+	hits := counter.NewSharded(0)
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				hits.Inc()
+			}
+		}()
+	}
+
+	wg.Wait()
+	fmt.Println("Final Counter:", hits.Load())
+
+	fmt.Println("-----------------------------------")
+
+	// Gauge with Minimum and Maximum
+	// A gauge goes up and down and remembers its extremes, e.g. the peak number of requests in flight
+
+	var inFlight counter.Gauge[int]
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inFlight.Add(1)
+			time.Sleep(10 * time.Millisecond) // Simulate a request
+			inFlight.Add(-1)
+		}()
+	}
+
+	wg.Wait()
+	fmt.Println("In flight now:", inFlight.Load(), "Peak:", inFlight.Max(), "Low:", inFlight.Min())
+
+	temperature := counter.NewGauge(21.5)
+	temperature.Add(-3.25)
+	temperature.Set(25)
+	fmt.Println("Temperature:", temperature.Load(), "Min:", temperature.Min(), "Max:", temperature.Max())
+}
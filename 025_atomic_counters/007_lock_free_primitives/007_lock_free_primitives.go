@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go_sample_examples/025_atomic_counters/lockfree"
+)
+
+const goroutines = 16
+
+func main() {
+
+	// Lock-Free Primitives Built on Compare-And-Swap
+	// Each primitive is stressed from many goroutines; run with `go run -race .` to check for data races
+
+	var wg sync.WaitGroup
+
+	// Treiber Stack
+	// Pushes and pops swap the head of a linked list with CAS, retrying when another goroutine got in first
+
+	var stack lockfree.Stack[int]
+	var popped sync.Map // value -> true, to detect duplicates
+	var pops, duplicates atomic.Int64
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				stack.Push(g*1000 + i)
+				if i%2 == 1 { // Pop about half as often as we push
+					if v, ok := stack.Pop(); ok {
+						pops.Add(1)
+						if _, loaded := popped.LoadOrStore(v, true); loaded {
+							duplicates.Add(1)
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	remaining := 0
+	for {
+		v, ok := stack.Pop()
+		if !ok {
+			break
+		}
+		remaining++
+		if _, loaded := popped.LoadOrStore(v, true); loaded {
+			duplicates.Add(1)
+		}
+	}
+	fmt.Println("Pushed:", goroutines*1000, "Popped concurrently + remaining:", int(pops.Load())+remaining)
+	fmt.Println("Values popped twice:", duplicates.Load())
+
+	fmt.Println("-----------------------------------")
+
+	// Atomic State Machine
+	// Every goroutine tries Idle -> Running -> Stopped; CAS lets exactly one of them win each transition
+
+	lifecycle := lockfree.NewLifecycle()
+	var started, stopped, lost atomic.Int64
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := lifecycle.Transition(lockfree.Idle, lockfree.Running); err == nil {
+				started.Add(1)
+			} else if errors.Is(err, lockfree.ErrStateChanged) {
+				lost.Add(1)
+			}
+			if err := lifecycle.Transition(lockfree.Running, lockfree.Stopped); err == nil {
+				stopped.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Println("Started:", started.Load(), "Stopped:", stopped.Load(), "Lost the race to start:", lost.Load())
+	fmt.Println("Final state:", lifecycle.Current())
+	fmt.Println("Restart:", lifecycle.Transition(lockfree.Stopped, lockfree.Running))
+
+	fmt.Println("-----------------------------------")
+
+	// OnceValue
+	// The first caller computes the value; the others wait on a channel instead of spinning
+
+	var calls atomic.Int64
+	settings := lockfree.NewOnceValue(func() map[string]string {
+		calls.Add(1)
+		return map[string]string{"region": "eu-west-1"}
+	})
+
+	var mismatches atomic.Int64
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if settings.Get()["region"] != "eu-west-1" {
+				mismatches.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Println("Computed:", calls.Load(), "time(s), wrong values seen:", mismatches.Load())
+}
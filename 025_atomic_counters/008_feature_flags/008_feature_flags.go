@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go_sample_examples/025_atomic_counters/featureflag"
+)
+
+// countEnabled checks the flag for 10,000 user IDs and returns how many have it on
+func countEnabled(flag *featureflag.Flag) int {
+	n := 0
+	for user := 0; user < 10000; user++ {
+		if flag.EnabledFor("user-" + strconv.Itoa(user)) {
+			n++
+		}
+	}
+	return n
+}
+
+func main() {
+
+	// Feature Flag Registry
+	// Named flags are loaded from a JSON file, checked with atomic loads only,
+	// rolled out to a share of users by hashing their ID, and reloaded when the file changes
+
+	dir, err := os.MkdirTemp("", "flags")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "flags.json")
+
+	os.WriteFile(path, []byte(`{
+		"dark-mode":    {"enabled": true},
+		"new-checkout": {"enabled": true, "rollout": 25},
+		"beta-search":  {"enabled": true}
+	}`), 0o644)
+
+	flags := featureflag.NewRegistry()
+	if err := flags.LoadFile(path); err != nil {
+		panic(err)
+	}
+
+	fmt.Println("dark-mode:", flags.Enabled("dark-mode"))
+	fmt.Println("beta-search:", flags.Enabled("beta-search"))
+	fmt.Println("unknown-flag:", flags.Enabled("unknown-flag"))
+
+	// Hot paths keep the *Flag handle; it stays valid across reloads
+	checkout := flags.Flag("new-checkout")
+	fmt.Printf("new-checkout at %.0f%%: %d of 10000 users\n", checkout.Rollout(), countEnabled(checkout))
+	fmt.Println("user-42 always gets the same answer:", checkout.EnabledFor("user-42") == flags.EnabledFor("new-checkout", "user-42"))
+
+	fmt.Println("-----------------------------------")
+
+	// Reload on File Change
+	// Watch polls the file and reloads it when its modification time or size changes
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go flags.Watch(ctx, path, 20*time.Millisecond, func(err error) {
+		fmt.Println("Reload failed:", err)
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(path, []byte(`{
+		"dark-mode":    {"enabled": true},
+		"new-checkout": {"enabled": true, "rollout": 50}
+	}`), 0o644)
+
+	for flags.Reloads() < 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	fmt.Printf("new-checkout at %.0f%%: %d of 10000 users\n", checkout.Rollout(), countEnabled(checkout))
+	fmt.Println("beta-search after being removed from the file:", flags.Enabled("beta-search"))
+
+	fmt.Println("-----------------------------------")
+
+	// Cost of a Check
+	// A check is one atomic load plus a hash for rollouts; it takes no lock and does not allocate
+
+	for _, bench := range []struct {
+		name string
+		fn   func()
+	}{
+		{"Registry.Enabled", func() { flags.Enabled("dark-mode") }},
+		{"Flag.EnabledFor", func() { checkout.EnabledFor("user-42") }},
+	} {
+		r := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bench.fn()
+			}
+		})
+		fmt.Printf("%-17s %5.1f ns/op %d allocs/op\n", bench.name, float64(r.T.Nanoseconds())/float64(r.N), r.AllocsPerOp())
+	}
+}
@@ -0,0 +1,135 @@
+// Package config holds a configuration value that many goroutines read and a
+// few replace, without locks on either path. It builds on the atomic.Value
+// example in 005_atomic_pointer with a typed atomic.Pointer: readers load the
+// current *T, writers publish a new copy with compare-and-swap, and every
+// change gets a version number and is announced to subscribers.
+//
+// Values are shared between goroutines once stored, so they must never be
+// modified in place; Update hands the old value to a function that returns a
+// modified copy. A Holder never holds nil, so Load always returns a usable
+// value.
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Change describes one update of a Holder.
+type Change[T any] struct {
+	Old     *T
+	New     *T
+	Version uint64
+}
+
+type snapshot[T any] struct {
+	value   *T
+	version uint64
+}
+
+// Holder is a copy-on-write holder for a *T.
+type Holder[T any] struct {
+	current atomic.Pointer[snapshot[T]]
+
+	mu   sync.Mutex                // guards subs; only taken by writers and subscribers
+	subs map[chan Change[T]]uint64 // last version sent on each channel
+}
+
+// New returns a holder with initial as version 1. It panics if initial is
+// nil.
+func New[T any](initial *T) *Holder[T] {
+	if initial == nil {
+		panic("config: New with a nil value")
+	}
+	h := &Holder[T]{subs: make(map[chan Change[T]]uint64)}
+	h.current.Store(&snapshot[T]{value: initial, version: 1})
+	return h
+}
+
+// Load returns the current value. It never blocks.
+func (h *Holder[T]) Load() *T {
+	return h.current.Load().value
+}
+
+// Snapshot returns the current value together with its version.
+func (h *Holder[T]) Snapshot() (*T, uint64) {
+	s := h.current.Load()
+	return s.value, s.version
+}
+
+// Version returns the version of the current value.
+func (h *Holder[T]) Version() uint64 {
+	return h.current.Load().version
+}
+
+// Store replaces the value unconditionally and returns its version. Like
+// atomic.Value.Store, it panics if v is nil: nil is what an Update function
+// returns for "no change", and readers would get a nil pointer from Load.
+func (h *Holder[T]) Store(v *T) uint64 {
+	if v == nil {
+		panic("config: Store of a nil value")
+	}
+	_, version, _ := h.Update(func(*T) *T { return v })
+	return version
+}
+
+// Update replaces the value with fn(old). If another writer got in first,
+// fn is called again with the newer value, so it must not have side effects
+// and must not modify old. Returning nil from fn leaves the value unchanged.
+// Update reports the resulting value and version and whether it changed.
+func (h *Holder[T]) Update(fn func(old *T) *T) (*T, uint64, bool) {
+	for {
+		old := h.current.Load()
+		v := fn(old.value)
+		if v == nil {
+			return old.value, old.version, false
+		}
+		next := &snapshot[T]{value: v, version: old.version + 1}
+		if h.current.CompareAndSwap(old, next) {
+			h.notify(Change[T]{Old: old.value, New: v, Version: next.version})
+			return v, next.version, true
+		}
+	}
+}
+
+// Subscribe returns a channel that receives the changes made after the call,
+// and a function that ends the subscription and closes the channel. A slow
+// subscriber does not hold up writers: it only gets the latest change it has
+// not read yet.
+func (h *Holder[T]) Subscribe() (<-chan Change[T], func()) {
+	ch := make(chan Change[T], 1)
+	h.mu.Lock()
+	h.subs[ch] = 0
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			close(ch)
+			h.mu.Unlock()
+		})
+	}
+}
+
+func (h *Holder[T]) notify(c Change[T]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch, sent := range h.subs {
+		// Writers notify outside the CAS, so a change can arrive after a
+		// newer one has been sent. Drop it instead of going back in time.
+		if c.Version <= sent {
+			continue
+		}
+		// Replace an unread change. The merged change keeps the oldest Old.
+		out := c
+		select {
+		case pending := <-ch:
+			out.Old = pending.Old
+		default:
+		}
+		ch <- out
+		h.subs[ch] = c.Version
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import "testing"
+
+type settings struct{ n int }
+
+func TestStoreAndUpdate(t *testing.T) {
+	h := New(&settings{n: 1})
+	if v := h.Store(&settings{n: 2}); v != 2 {
+		t.Fatalf("Store returned version %d, want 2", v)
+	}
+
+	// Returning nil from fn leaves the value alone
+	if _, v, changed := h.Update(func(*settings) *settings { return nil }); changed || v != 2 {
+		t.Fatalf("Update to nil = version %d, changed %v, want 2 and false", v, changed)
+	}
+	got, v, changed := h.Update(func(old *settings) *settings { return &settings{n: old.n + 1} })
+	if !changed || v != 3 || got.n != 3 || h.Load().n != 3 {
+		t.Fatalf("Update = %+v at version %d, changed %v, want n 3 at version 3", got, v, changed)
+	}
+}
+
+func TestNilValuesPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"New", func() { New[settings](nil) }},
+		{"Store", func() { New(&settings{}).Store(nil) }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("did not panic on a nil value")
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
+
+func TestSubscribeGetsLatestChange(t *testing.T) {
+	h := New(&settings{n: 1})
+	changes, unsubscribe := h.Subscribe()
+	h.Store(&settings{n: 2})
+	h.Store(&settings{n: 3})
+	unsubscribe()
+
+	// The unread change was merged and is still delivered after unsubscribing
+	c, ok := <-changes
+	if !ok || c.Old.n != 1 || c.New.n != 3 || c.Version != 3 {
+		t.Fatalf("got %+v, %v, want 1 -> 3 at version 3", c, ok)
+	}
+	if _, ok := <-changes; ok {
+		t.Fatal("channel not closed after unsubscribe")
+	}
+}
@@ -0,0 +1,77 @@
+// Package counter provides counters for hot paths written to by many
+// goroutines at once. A single atomic int32, as in 025_atomic_counters, or a
+// mutex-protected int, like SafeCounter in 011_goroutine_channel, makes every
+// core fight over the same cache line. Sharded spreads the writes over
+// padded cells and only adds them up when it is read.
+package counter
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
+// cacheLine is the size of a CPU cache line on common amd64 and arm64 cores.
+const cacheLine = 64
+
+// cell is a counter padded to a full cache line, so that two cells never
+// share one and writes to one do not invalidate the other.
+type cell struct {
+	n atomic.Int64
+	_ [cacheLine - 8]byte
+}
+
+// Sharded is a counter that is cheap to update concurrently and slower to
+// read. Use it for counts that are written far more often than they are read,
+// such as request or byte counters.
+type Sharded struct {
+	cells []cell
+	mask  uint32
+}
+
+// NewSharded returns a counter with the given number of shards, rounded up to
+// a power of two. With shards <= 0 it uses four per GOMAXPROCS.
+func NewSharded(shards int) *Sharded {
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	n := 1 << bits.Len(uint(shards-1))
+	return &Sharded{cells: make([]cell, n), mask: uint32(n - 1)}
+}
+
+// Add adds delta to the counter.
+func (s *Sharded) Add(delta int64) {
+	// Go has no goroutine or CPU ids to pick a shard with; the runtime's
+	// per-thread random source is cheap and spreads the writers out.
+	s.cells[rand.Uint32()&s.mask].n.Add(delta)
+}
+
+// Inc adds one to the counter.
+func (s *Sharded) Inc() {
+	s.Add(1)
+}
+
+// Load returns the sum of all shards. Updates that run concurrently with
+// Load may or may not be included.
+func (s *Sharded) Load() int64 {
+	var sum int64
+	for i := range s.cells {
+		sum += s.cells[i].n.Load()
+	}
+	return sum
+}
+
+// Reset sets the counter to zero and returns the value it had.
+func (s *Sharded) Reset() int64 {
+	var sum int64
+	for i := range s.cells {
+		sum += s.cells[i].n.Swap(0)
+	}
+	return sum
+}
+
+// Shards returns the number of shards.
+func (s *Sharded) Shards() int {
+	return len(s.cells)
+}
@@ -0,0 +1,89 @@
+package counter
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestShardedConcurrentInc(t *testing.T) {
+	c := NewSharded(0)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				c.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	if got := c.Load(); got != 8000 {
+		t.Fatalf("Load = %d, want 8000", got)
+	}
+	if got := c.Reset(); got != 8000 || c.Load() != 0 {
+		t.Fatalf("Reset = %d leaving %d, want 8000 leaving 0", got, c.Load())
+	}
+}
+
+func TestNewShardedRoundsUp(t *testing.T) {
+	for _, tc := range []struct{ shards, want int }{{1, 1}, {3, 4}, {8, 8}, {9, 16}} {
+		if got := NewSharded(tc.shards).Shards(); got != tc.want {
+			t.Errorf("NewSharded(%d).Shards() = %d, want %d", tc.shards, got, tc.want)
+		}
+	}
+}
+
+// safeCounter is the mutex-protected SafeCounter from 011_goroutine_channel.
+type safeCounter struct {
+	mu    sync.Mutex
+	value int
+}
+
+func (sc *safeCounter) Increment() {
+	sc.mu.Lock()
+	sc.value++
+	sc.mu.Unlock()
+}
+
+// benchmarkGoroutines runs b.N calls of inc spread over exactly the given
+// number of goroutines, for each of the goroutine counts.
+func benchmarkGoroutines(b *testing.B, inc func()) {
+	for _, goroutines := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("goroutines=%d", goroutines), func(b *testing.B) {
+			var wg sync.WaitGroup
+			for g := range goroutines {
+				// Share b.N out so the goroutines do all of it between them
+				n := b.N / goroutines
+				if g < b.N%goroutines {
+					n++
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range n {
+						inc()
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func BenchmarkSafeCounter(b *testing.B) {
+	var c safeCounter
+	benchmarkGoroutines(b, c.Increment)
+}
+
+func BenchmarkAtomicAddInt32(b *testing.B) {
+	var n int32
+	benchmarkGoroutines(b, func() { atomic.AddInt32(&n, 1) })
+}
+
+func BenchmarkSharded(b *testing.B) {
+	c := NewSharded(0)
+	benchmarkGoroutines(b, c.Inc)
+}
@@ -0,0 +1,108 @@
+package counter
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Number is the set of types a Gauge can hold.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Gauge holds a value that goes up and down, such as the number of requests
+// in flight, and remembers the lowest and highest values it has had. All
+// methods are lock-free. The zero value is a gauge at 0.
+type Gauge[T Number] struct {
+	value, min, max atomic.Uint64 // bit patterns of T
+}
+
+// NewGauge returns a gauge set to initial.
+func NewGauge[T Number](initial T) *Gauge[T] {
+	g := &Gauge[T]{}
+	b := toBits(initial)
+	g.value.Store(b)
+	g.min.Store(b)
+	g.max.Store(b)
+	return g
+}
+
+// Load returns the current value.
+func (g *Gauge[T]) Load() T {
+	return fromBits[T](g.value.Load())
+}
+
+// Set stores v.
+func (g *Gauge[T]) Set(v T) {
+	g.value.Store(toBits(v))
+	g.observe(v)
+}
+
+// Add adds delta, which may be negative for signed and float types, and
+// returns the new value.
+func (g *Gauge[T]) Add(delta T) T {
+	for {
+		old := g.value.Load()
+		v := fromBits[T](old) + delta
+		if g.value.CompareAndSwap(old, toBits(v)) {
+			g.observe(v)
+			return v
+		}
+	}
+}
+
+// Min returns the lowest value the gauge has had since it was created or
+// last reset.
+func (g *Gauge[T]) Min() T {
+	return fromBits[T](g.min.Load())
+}
+
+// Max returns the highest value the gauge has had since it was created or
+// last reset.
+func (g *Gauge[T]) Max() T {
+	return fromBits[T](g.max.Load())
+}
+
+// ResetExtremes sets the minimum and maximum to the current value and
+// returns the previous ones, to track extremes per reporting interval.
+func (g *Gauge[T]) ResetExtremes() (lo, hi T) {
+	cur := g.value.Load()
+	return fromBits[T](g.min.Swap(cur)), fromBits[T](g.max.Swap(cur))
+}
+
+func (g *Gauge[T]) observe(v T) {
+	update(&g.min, v, func(v, cur T) bool { return v < cur })
+	update(&g.max, v, func(v, cur T) bool { return v > cur })
+}
+
+// update stores v in a while better(v, current) holds, retrying on races.
+func update[T Number](a *atomic.Uint64, v T, better func(v, cur T) bool) {
+	for {
+		old := a.Load()
+		if !better(v, fromBits[T](old)) || a.CompareAndSwap(old, toBits(v)) {
+			return
+		}
+	}
+}
+
+// isFloat reports whether T is a floating-point type.
+func isFloat[T Number]() bool {
+	var one T = 1
+	return one/2 != 0
+}
+
+func toBits[T Number](v T) uint64 {
+	if isFloat[T]() {
+		return math.Float64bits(float64(v))
+	}
+	return uint64(int64(v))
+}
+
+func fromBits[T Number](b uint64) T {
+	if isFloat[T]() {
+		return T(math.Float64frombits(b))
+	}
+	return T(int64(b))
+}
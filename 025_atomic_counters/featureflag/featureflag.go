@@ -0,0 +1,272 @@
+// Package featureflag is a registry of named feature flags for hot paths. It
+// extends the single atomic flag in 003_atomic_flag_using_sync_atomic: every
+// check is a single atomic load and never takes a lock, flags are loaded from a
+// JSON file that can be watched for changes, and a flag can be rolled out to
+// a percentage of users picked by hashing a key such as the user ID.
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+// scale is the rollout resolution: percentages are stored in hundredths.
+const scale = 10000
+
+// Flag is one feature flag. A *Flag stays valid across reloads, so hot paths
+// can keep it instead of looking the flag up by name on every check.
+type Flag struct {
+	name  string
+	state atomic.Pointer[flagState] // replaced as a whole, never modified
+}
+
+// flagState is the setting of a flag. Keeping both fields behind one pointer
+// means a check never sees the enabled bit of one setting with the rollout of
+// another.
+type flagState struct {
+	enabled bool
+	rollout uint32 // share of keys enabled, in hundredths of a percent
+}
+
+// Name returns the flag's name.
+func (f *Flag) Name() string {
+	return f.name
+}
+
+// Enabled reports whether the flag is on for everyone.
+func (f *Flag) Enabled() bool {
+	s := f.state.Load()
+	return s.enabled && s.rollout >= scale
+}
+
+// EnabledFor reports whether the flag is on for key. The same key always gets
+// the same answer for a given rollout percentage, and raising the percentage
+// only adds keys.
+func (f *Flag) EnabledFor(key string) bool {
+	s := f.state.Load()
+	if !s.enabled {
+		return false
+	}
+	if s.rollout >= scale {
+		return true
+	}
+	return bucket(f.name, key) < s.rollout
+}
+
+// Rollout returns the rollout percentage.
+func (f *Flag) Rollout() float64 {
+	return float64(f.state.Load().rollout) / (scale / 100)
+}
+
+func (f *Flag) set(enabled bool, rollout float64) {
+	f.state.Store(&flagState{
+		enabled: enabled,
+		rollout: uint32(min(max(rollout, 0), 100) * (scale / 100)),
+	})
+}
+
+// bucket maps name and key to [0, scale). Hashing the name too gives every
+// flag a different set of early adopters.
+func bucket(name, key string) uint32 {
+	// FNV-1a, written out so that the hot path does not allocate
+	const offset, prime = 2166136261, 16777619
+	h := uint32(offset)
+	for i := 0; i < len(name); i++ {
+		h = (h ^ uint32(name[i])) * prime
+	}
+	h *= prime // a zero byte between name and key
+	for i := 0; i < len(key); i++ {
+		h = (h ^ uint32(key[i])) * prime
+	}
+	return h % scale
+}
+
+// Config is the JSON form of one flag. Rollout defaults to 100.
+type Config struct {
+	Enabled bool     `json:"enabled"`
+	Rollout *float64 `json:"rollout,omitempty"`
+}
+
+// Registry holds the flags by name.
+type Registry struct {
+	flags atomic.Pointer[map[string]*Flag] // replaced, never modified
+	clock clock.Clock
+
+	mu      sync.Mutex // serializes writers
+	reloads atomic.Int64
+}
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithClock makes Watch poll on c instead of the system clock, so tests can
+// drive it with a clock.Fake.
+func WithClock(c clock.Clock) Option {
+	return func(r *Registry) {
+		r.clock = c
+	}
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.flags.Store(&map[string]*Flag{})
+	return r
+}
+
+// Flag returns the flag called name, creating it disabled if it does not
+// exist yet.
+func (r *Registry) Flag(name string) *Flag {
+	if f, ok := (*r.flags.Load())[name]; ok {
+		return f
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flagLocked(name)
+}
+
+// flagLocked must be called with mu held.
+func (r *Registry) flagLocked(name string) *Flag {
+	old := *r.flags.Load()
+	if f, ok := old[name]; ok {
+		return f
+	}
+	next := make(map[string]*Flag, len(old)+1)
+	for k, v := range old {
+		next[k] = v
+	}
+	f := &Flag{name: name}
+	f.state.Store(&flagState{})
+	next[name] = f
+	r.flags.Store(&next)
+	return f
+}
+
+// Enabled reports whether the flag called name is on for everyone. Unknown
+// flags are off.
+func (r *Registry) Enabled(name string) bool {
+	f, ok := (*r.flags.Load())[name]
+	return ok && f.Enabled()
+}
+
+// EnabledFor reports whether the flag called name is on for key. Unknown
+// flags are off.
+func (r *Registry) EnabledFor(name, key string) bool {
+	f, ok := (*r.flags.Load())[name]
+	return ok && f.EnabledFor(key)
+}
+
+// Set turns a flag on or off for the given rollout percentage.
+func (r *Registry) Set(name string, enabled bool, rollout float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flagLocked(name).set(enabled, rollout)
+}
+
+// Snapshot returns the configuration of every flag.
+func (r *Registry) Snapshot() map[string]Config {
+	flags := *r.flags.Load()
+	out := make(map[string]Config, len(flags))
+	for name, f := range flags {
+		s := f.state.Load()
+		rollout := float64(s.rollout) / (scale / 100)
+		out[name] = Config{Enabled: s.enabled, Rollout: &rollout}
+	}
+	return out
+}
+
+// Load replaces the flags with configs. Flags missing from configs are
+// turned off rather than removed, so that handles to them stay valid.
+func (r *Registry) Load(configs map[string]Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, f := range *r.flags.Load() {
+		if _, ok := configs[name]; !ok {
+			f.set(false, 0)
+		}
+	}
+	for name, c := range configs {
+		rollout := 100.0
+		if c.Rollout != nil {
+			rollout = *c.Rollout
+		}
+		r.flagLocked(name).set(c.Enabled, rollout)
+	}
+	r.reloads.Add(1)
+}
+
+// LoadFile loads the flags from a JSON file holding an object that maps
+// flag names to their Config, e.g. {"new-checkout": {"enabled": true,
+// "rollout": 25}}.
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("featureflag: %w", err)
+	}
+	var configs map[string]Config
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return fmt.Errorf("featureflag: parse %s: %w", path, err)
+	}
+	r.Load(configs)
+	return nil
+}
+
+// Reloads returns how many times the flags have been loaded.
+func (r *Registry) Reloads() int {
+	return int(r.reloads.Load())
+}
+
+// Watch polls path every interval and reloads the flags whenever the file's
+// modification time or size changes, until ctx is done. Errors are passed to
+// onError, if set, and the previous flags stay in effect; a file that fails
+// to load is read again on every poll until it loads, so one caught halfway
+// through being written is picked up once it is complete. An interval that
+// is not positive defaults to one second.
+func (r *Registry) Watch(ctx context.Context, path string, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	var lastMod time.Time
+	var lastSize int64 = -1
+	if info, err := os.Stat(path); err == nil {
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+
+	ticker := r.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+			return
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if onError != nil {
+				onError(fmt.Errorf("featureflag: %w", err))
+			}
+			continue
+		}
+		if info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+			continue
+		}
+		if err := r.LoadFile(path); err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			continue
+		}
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+}
@@ -0,0 +1,187 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go_sample_examples/020_timers/clock"
+)
+
+func TestRollout(t *testing.T) {
+	r := NewRegistry()
+	f := r.Flag("checkout")
+
+	enabledAt := func(rollout float64) map[string]bool {
+		r.Set("checkout", true, rollout)
+		on := make(map[string]bool)
+		for i := range 10000 {
+			if key := fmt.Sprint("user-", i); f.EnabledFor(key) {
+				on[key] = true
+			}
+		}
+		return on
+	}
+
+	prev := map[string]bool{}
+	for _, rollout := range []float64{0, 10, 25, 50, 100} {
+		on := enabledAt(rollout)
+		// Within a percentage point of the rollout
+		if share := float64(len(on)) / 100; share < rollout-1 || share > rollout+1 {
+			t.Errorf("%v%% rollout enabled %.2f%% of keys", rollout, share)
+		}
+		// Raising the rollout only adds keys
+		for key := range prev {
+			if !on[key] {
+				t.Errorf("%s was dropped when the rollout was raised to %v%%", key, rollout)
+			}
+		}
+		prev = on
+	}
+
+	r.Set("checkout", false, 100)
+	if f.EnabledFor("user-1") || f.Enabled() {
+		t.Fatal("disabled flag is on")
+	}
+}
+
+func TestBucketDependsOnFlagName(t *testing.T) {
+	same := 0
+	for i := range 1000 {
+		key := fmt.Sprint("user-", i)
+		if bucket("a", key) == bucket("b", key) {
+			same++
+		}
+	}
+	if same > 10 {
+		t.Fatalf("%d of 1000 keys got the same bucket for two flags", same)
+	}
+}
+
+func TestSetClampsRollout(t *testing.T) {
+	r := NewRegistry()
+	tests := []struct{ rollout, want float64 }{{-5, 0}, {12.5, 12.5}, {250, 100}}
+	for _, tc := range tests {
+		r.Set("f", true, tc.rollout)
+		if got := r.Flag("f").Rollout(); got != tc.want {
+			t.Errorf("Set(%v): Rollout = %v, want %v", tc.rollout, got, tc.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	r := NewRegistry()
+	old := r.Flag("old")
+	r.Set("old", true, 100)
+
+	half := 50.0
+	r.Load(map[string]Config{"new": {Enabled: true}, "half": {Enabled: true, Rollout: &half}})
+	if old.Enabled() || r.Enabled("old") {
+		t.Error("flag missing from the config is still on")
+	}
+	if !r.Enabled("new") || r.Flag("new").Rollout() != 100 {
+		t.Error("flag without a rollout is not fully on")
+	}
+	if r.Enabled("half") || r.Flag("half").Rollout() != 50 {
+		t.Error("flag at 50% is on for everyone")
+	}
+	if r.Enabled("unknown") || r.EnabledFor("unknown", "user-1") {
+		t.Error("unknown flag is on")
+	}
+	if got := r.Reloads(); got != 1 {
+		t.Errorf("Reloads = %d, want 1", got)
+	}
+}
+
+func TestLoadFileParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	r := NewRegistry()
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", `{"dark-mode": {"enabled": true}}`, false},
+		{"truncated", `{"dark-mode": {"enab`, true},
+		{"wrong type", `{"dark-mode": {"enabled": "yes"}}`, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			os.WriteFile(path, []byte(tc.content), 0o644)
+			if err := r.LoadFile(path); (err != nil) != tc.wantErr {
+				t.Fatalf("LoadFile = %v, want error %v", err, tc.wantErr)
+			}
+			// A bad file leaves the flags as they were
+			if !r.Enabled("dark-mode") {
+				t.Fatal("dark-mode is off")
+			}
+		})
+	}
+	if err := r.LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("LoadFile of a missing file returned nil")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	os.WriteFile(path, []byte(`{"dark-mode": {"enabled": false}}`), 0o644)
+	fake := clock.NewFake(time.Time{})
+	r := NewRegistry(WithClock(fake))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 10)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		r.Watch(ctx, path, time.Second, func(err error) { errs <- err })
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+	fake.BlockUntil(1) // the ticker
+
+	// poll advances the fake clock by one interval and waits for the watcher
+	// to reload the file or report an error
+	poll := func() error {
+		t.Helper()
+		reloads := r.Reloads()
+		fake.Advance(time.Second)
+		deadline := time.Now().Add(time.Second)
+		for r.Reloads() == reloads {
+			select {
+			case err := <-errs:
+				return err
+			default:
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("the watcher neither reloaded nor reported an error")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}
+
+	os.WriteFile(path, []byte(`{"dark-mode": {"enabled": true}}`), 0o644)
+	if err := poll(); err != nil || !r.Enabled("dark-mode") {
+		t.Fatalf("after a change: err %v, dark-mode %v, want it reloaded and on", err, r.Enabled("dark-mode"))
+	}
+
+	// A file caught halfway through being written fails to parse. It is read
+	// again on the next poll even if its size and time no longer change.
+	complete := []byte(`{"dark-mode": {"enabled": false}}`)
+	partial := append([]byte(`{"dark-mode": {"enab`), make([]byte, len(complete)-20)...)
+	os.WriteFile(path, partial, 0o644)
+	info, _ := os.Stat(path)
+	if err := poll(); err == nil {
+		t.Fatal("partial file loaded without an error")
+	}
+	os.WriteFile(path, complete, 0o644)
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	if err := poll(); err != nil || r.Enabled("dark-mode") {
+		t.Fatalf("after completing the file: err %v, dark-mode %v, want it reloaded and off", err, r.Enabled("dark-mode"))
+	}
+}
@@ -0,0 +1,58 @@
+package lockfree
+
+import "sync/atomic"
+
+const (
+	onceIdle int32 = iota
+	onceRunning
+	onceDone
+)
+
+// OnceValue computes a value the first time it is needed. The first caller
+// runs the function; callers that arrive while it runs block on a channel
+// rather than spinning, and later callers only pay for an atomic load.
+type OnceValue[T any] struct {
+	fn    func() T
+	state atomic.Int32
+	done  chan struct{}
+	value T
+	panic any
+}
+
+// NewOnceValue returns a OnceValue that computes its value with fn.
+func NewOnceValue[T any](fn func() T) *OnceValue[T] {
+	return &OnceValue[T]{fn: fn, done: make(chan struct{})}
+}
+
+// Get returns the value, computing it on the first call. If fn panicked,
+// every call panics with the same value.
+func (o *OnceValue[T]) Get() T {
+	if o.state.Load() != onceDone {
+		if o.state.CompareAndSwap(onceIdle, onceRunning) {
+			o.run()
+		} else {
+			<-o.done
+		}
+	}
+	if o.panic != nil {
+		panic(o.panic)
+	}
+	return o.value
+}
+
+// Done reports whether the value has been computed.
+func (o *OnceValue[T]) Done() bool {
+	return o.state.Load() == onceDone
+}
+
+func (o *OnceValue[T]) run() {
+	defer func() {
+		if r := recover(); r != nil {
+			o.panic = r
+		}
+		o.fn = nil
+		o.state.Store(onceDone) // publishes value and panic to the fast path
+		close(o.done)
+	}()
+	o.value = o.fn()
+}
@@ -0,0 +1,76 @@
+package lockfree
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestOnceValueCalledOnce has many goroutines ask for the value while fn is
+// still running and checks that fn runs once and everyone sees its result.
+func TestOnceValueCalledOnce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	o := NewOnceValue(func() int {
+		calls.Add(1)
+		<-release
+		return 42
+	})
+
+	const goroutines = 32
+	results := make(chan int, goroutines)
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- o.Get()
+		}()
+	}
+
+	// Let the goroutines pile up behind the first caller
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if o.Done() {
+		t.Fatal("Done reported true while fn was running")
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for v := range results {
+		if v != 42 {
+			t.Fatalf("Get = %d, want 42", v)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("fn called %d times, want 1", n)
+	}
+	if !o.Done() {
+		t.Fatal("Done reported false after Get returned")
+	}
+}
+
+func TestOnceValuePanic(t *testing.T) {
+	var calls atomic.Int32
+	o := NewOnceValue(func() int {
+		calls.Add(1)
+		panic("boom")
+	})
+
+	for range 3 {
+		func() {
+			defer func() {
+				if r := recover(); r != "boom" {
+					t.Fatalf("recovered %v, want boom", r)
+				}
+			}()
+			o.Get()
+		}()
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("fn called %d times, want 1", n)
+	}
+}
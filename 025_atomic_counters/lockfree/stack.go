@@ -0,0 +1,61 @@
+// Package lockfree collects small concurrent primitives built on
+// compare-and-swap, extending the single CompareAndSwapInt32 call shown in
+// 025_atomic_counters: a Treiber stack, a state machine with validated
+// transitions and a OnceValue whose waiters block instead of spinning.
+package lockfree
+
+import "sync/atomic"
+
+type node[T any] struct {
+	value T
+	next  *node[T]
+}
+
+// Stack is a Treiber stack: a linked list whose head is swapped in with
+// compare-and-swap. Nodes are never reused, so the garbage collector rules
+// out the ABA problem. The zero value is an empty stack.
+type Stack[T any] struct {
+	head atomic.Pointer[node[T]]
+	size atomic.Int64
+}
+
+// Push adds v to the top of the stack.
+func (s *Stack[T]) Push(v T) {
+	n := &node[T]{value: v}
+	for {
+		n.next = s.head.Load()
+		if s.head.CompareAndSwap(n.next, n) {
+			s.size.Add(1)
+			return
+		}
+	}
+}
+
+// Pop removes the value at the top of the stack. ok is false when the stack
+// is empty.
+func (s *Stack[T]) Pop() (v T, ok bool) {
+	for {
+		top := s.head.Load()
+		if top == nil {
+			return v, false
+		}
+		if s.head.CompareAndSwap(top, top.next) {
+			s.size.Add(-1)
+			return top.value, true
+		}
+	}
+}
+
+// Peek returns the value at the top of the stack without removing it.
+func (s *Stack[T]) Peek() (v T, ok bool) {
+	if top := s.head.Load(); top != nil {
+		return top.value, true
+	}
+	return v, false
+}
+
+// Len returns the number of values on the stack. It may lag behind
+// concurrent pushes and pops.
+func (s *Stack[T]) Len() int {
+	return int(s.size.Load())
+}
@@ -0,0 +1,69 @@
+package lockfree
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestStackLIFO(t *testing.T) {
+	var s Stack[int]
+	if _, ok := s.Pop(); ok {
+		t.Fatal("Pop on an empty stack reported a value")
+	}
+	for i := 1; i <= 3; i++ {
+		s.Push(i)
+	}
+	if v, ok := s.Peek(); !ok || v != 3 {
+		t.Fatalf("Peek = %d, %v, want 3, true", v, ok)
+	}
+	for want := 3; want >= 1; want-- {
+		if v, ok := s.Pop(); !ok || v != want {
+			t.Fatalf("Pop = %d, %v, want %d, true", v, ok, want)
+		}
+	}
+	if s.Len() != 0 {
+		t.Fatalf("Len = %d after popping everything, want 0", s.Len())
+	}
+}
+
+// TestStackConcurrent pushes and pops from many goroutines at once and
+// checks that every pushed value is popped exactly once.
+func TestStackConcurrent(t *testing.T) {
+	const goroutines, perGoroutine = 8, 1000
+	var s Stack[int]
+
+	var wg sync.WaitGroup
+	popped := make([][]int, goroutines)
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perGoroutine {
+				s.Push(g*perGoroutine + i)
+				if v, ok := s.Pop(); ok {
+					popped[g] = append(popped[g], v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var all []int
+	for _, values := range popped {
+		all = append(all, values...)
+	}
+	for v, ok := s.Pop(); ok; v, ok = s.Pop() {
+		all = append(all, v)
+	}
+
+	slices.Sort(all)
+	if len(all) != goroutines*perGoroutine {
+		t.Fatalf("popped %d values, pushed %d", len(all), goroutines*perGoroutine)
+	}
+	for i, v := range all {
+		if v != i {
+			t.Fatalf("value %d popped twice or lost", i)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package lockfree
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrInvalidTransition is returned for a transition the machine does not
+// allow.
+var ErrInvalidTransition = errors.New("lockfree: invalid state transition")
+
+// ErrStateChanged is returned when the machine was not in the expected state,
+// usually because another goroutine made a transition first.
+var ErrStateChanged = errors.New("lockfree: state changed concurrently")
+
+// State is the state of a Machine.
+type State int32
+
+// The states of the lifecycle built by NewLifecycle.
+const (
+	Idle State = iota
+	Running
+	Stopped
+)
+
+func (s State) String() string {
+	switch s {
+	case Idle:
+		return "Idle"
+	case Running:
+		return "Running"
+	case Stopped:
+		return "Stopped"
+	}
+	return fmt.Sprintf("State(%d)", int32(s))
+}
+
+// Machine is a state machine whose transitions are made with
+// compare-and-swap, so exactly one of several goroutines racing to make the
+// same transition wins.
+type Machine struct {
+	state       atomic.Int32
+	transitions map[State]map[State]bool // read-only after construction
+}
+
+// NewMachine returns a machine in the initial state that allows the given
+// transitions, from each key to any of its states.
+func NewMachine(initial State, transitions map[State][]State) *Machine {
+	m := &Machine{transitions: make(map[State]map[State]bool, len(transitions))}
+	for from, tos := range transitions {
+		m.transitions[from] = make(map[State]bool, len(tos))
+		for _, to := range tos {
+			m.transitions[from][to] = true
+		}
+	}
+	m.state.Store(int32(initial))
+	return m
+}
+
+// NewLifecycle returns a machine for a service that starts once and stops
+// once: Idle → Running → Stopped, or Idle → Stopped if it never started.
+func NewLifecycle() *Machine {
+	return NewMachine(Idle, map[State][]State{
+		Idle:    {Running, Stopped},
+		Running: {Stopped},
+	})
+}
+
+// Current returns the current state.
+func (m *Machine) Current() State {
+	return State(m.state.Load())
+}
+
+// Transition moves the machine from one state to another. It fails with
+// ErrInvalidTransition if the move is not allowed and with ErrStateChanged
+// if the machine is not in from.
+func (m *Machine) Transition(from, to State) error {
+	if !m.transitions[from][to] {
+		return fmt.Errorf("%w: %v → %v", ErrInvalidTransition, from, to)
+	}
+	if !m.state.CompareAndSwap(int32(from), int32(to)) {
+		return fmt.Errorf("%w: expected %v, found %v", ErrStateChanged, from, m.Current())
+	}
+	return nil
+}
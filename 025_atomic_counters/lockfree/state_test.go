@@ -0,0 +1,77 @@
+package lockfree
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLifecycleTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    State
+		to      State
+		current State // state of the machine before the transition
+		wantErr error
+	}{
+		{"start", Idle, Running, Idle, nil},
+		{"stop", Running, Stopped, Running, nil},
+		{"stop without starting", Idle, Stopped, Idle, nil},
+		{"restart", Stopped, Running, Stopped, ErrInvalidTransition},
+		{"back to idle", Running, Idle, Running, ErrInvalidTransition},
+		{"stale from", Idle, Running, Stopped, ErrStateChanged},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewLifecycle()
+			m.state.Store(int32(tc.current))
+			err := m.Transition(tc.from, tc.to)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Transition(%v, %v) = %v, want %v", tc.from, tc.to, err, tc.wantErr)
+			}
+			want := tc.current
+			if err == nil {
+				want = tc.to
+			}
+			if got := m.Current(); got != want {
+				t.Fatalf("Current = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestTransitionSingleWinner races many goroutines on the same transition
+// and checks that exactly one of them makes it.
+func TestTransitionSingleWinner(t *testing.T) {
+	for range 100 {
+		m := NewLifecycle()
+		var wins, lost atomic.Int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for range 16 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				switch err := m.Transition(Idle, Running); {
+				case err == nil:
+					wins.Add(1)
+				case errors.Is(err, ErrStateChanged):
+					lost.Add(1)
+				default:
+					t.Errorf("Transition: %v", err)
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if wins.Load() != 1 || lost.Load() != 15 {
+			t.Fatalf("%d winners and %d losers, want 1 and 15", wins.Load(), lost.Load())
+		}
+		if m.Current() != Running {
+			t.Fatalf("Current = %v, want %v", m.Current(), Running)
+		}
+	}
+}
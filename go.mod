@@ -1 +1,3 @@
 module go_sample_examples
+
+go 1.23